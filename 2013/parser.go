@@ -5,9 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
-	"unsafe"
 
-	bits "github.com/chanyk-joseph/gobits"
 	common "github.com/chanyk-joseph/scte35_decoder/common"
 )
 
@@ -36,196 +34,353 @@ type SpliceDescriptor struct {
 }
 
 func (spliceDesc *SpliceDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
-
-	spliceDesc.SpliceDescriptorTag, _, err = bits.Byte(input, numOfParsedBits)
-	numOfParsedBits += 8
+	r := common.NewBitReader(input)
+	spliceDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-	spliceDesc.DescriptorLength, _, err = bits.Uint8(input, numOfParsedBits)
-	numOfParsedBits += 8
+//DecodeFromBitReader parses SpliceDescriptor object from r, the bytes not used by the descriptor of the tag are kept as private bytes
+func (spliceDesc *SpliceDescriptor) DecodeFromBitReader(r *common.BitReader) {
+	spliceDesc.SpliceDescriptorTag = common.SpliceDescriptorTag(r.Uint8("splice_descriptor_tag"))
+	spliceDesc.DescriptorLength = r.Uint8("descriptor_length")
+	if r.Err() == nil && spliceDesc.DescriptorLength < 4 {
+		r.Fail("descriptor_length", "descriptor_length("+strconv.Itoa(int(spliceDesc.DescriptorLength))+") is less than the 4 bytes of identifier")
+		return
+	}
 
-	spliceDesc.Identifier, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
+	body := r.Sub("", int(spliceDesc.DescriptorLength)*8)
+	spliceDesc.Identifier = body.Uint32("identifier")
 
-	numOfBitsLeft := int(spliceDesc.DescriptorLength-4) * 8 // -4 for identifier
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, numOfBitsLeft)
-	spliceDescriptorUsedBits := 0
 	switch spliceDesc.SpliceDescriptorTag {
 	case 0x00:
 		availDesc := &common.AvailDescriptor{}
-		spliceDescriptorUsedBits, err = availDesc.DecodeFromRawBytes(tmpBytes)
+		sub := body.Sub("avail_descriptor", -1)
+		availDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
 
 		spliceDesc.AvailDescriptor = availDesc
 	case 0x01:
 		dtmfDesc := &common.DTMFDescriptor{}
-		spliceDescriptorUsedBits, err = dtmfDesc.DecodeFromRawBytes(tmpBytes)
+		sub := body.Sub("dtmf_descriptor", -1)
+		dtmfDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
 
 		spliceDesc.DTMFDescriptor = dtmfDesc
 	case 0x02:
 		segDesc := &common.SegmentationDescriptor{}
-		spliceDescriptorUsedBits, err = segDesc.DecodeFromRawBytes(tmpBytes)
+		sub := body.Sub("segmentation_descriptor", -1)
+		segDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
 
 		spliceDesc.SegmentationDescriptor = segDesc
 	}
-	numOfParsedBits += spliceDescriptorUsedBits
 
-	numOfBitsLeftForPrivateBytes := int(spliceDesc.DescriptorLength-4)*8 - spliceDescriptorUsedBits
-	if numOfBitsLeftForPrivateBytes < 0 {
-		spliceDesc = &SpliceDescriptor{}
-		return 0, errors.New("The number of bytes used by splice descriptor is more than descriptor_length")
-	}
-	if numOfBitsLeftForPrivateBytes > 0 {
-		_, spliceDesc.PrivateByteInHex, err = bits.HexString(input, numOfParsedBits, numOfBitsLeftForPrivateBytes)
-		numOfParsedBits += numOfBitsLeftForPrivateBytes
+	if body.Err() == nil && body.Left() > 0 {
+		privateByteInHex := body.HexString("private_byte", body.Left()/8)
+		spliceDesc.PrivateByteInHex = &privateByteInHex
 	}
-
-	return numOfParsedBits, err
+	r.Merge(body)
 }
 
-func (scte35 *SCTE35) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+//EncodeToRawBytes serializes SpliceDescriptor object to []byte, descriptor_length is recalculated
+func (spliceDesc *SpliceDescriptor) EncodeToRawBytes() (output []byte, err error) {
 	var tmpBytes []byte
+	w := &common.BitWriter{}
 
-	scte35.TableID, _, err = bits.Uint8(input, numOfParsedBits)
-	numOfParsedBits += 8
-
-	scte35.SectionSyntaxIndicator, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
-
-	scte35.PrivateIndicator, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
-
-	numOfParsedBits += 2 //reserved 2 bits
-
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 12)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 4)
-	scte35.SectionLength, _, err = bits.Uint16(tmpBytes, 0)
-	numOfParsedBits += 12
+	if spliceDesc.UndecodedBytesInHex != nil {
+		if err = w.WriteHexString(*spliceDesc.UndecodedBytesInHex); err != nil {
+			return nil, err
+		}
+		return spliceDesc.encodeWithHeader(w)
+	}
 
-	scte35.ProtocolVersion, _, err = bits.Uint8(input, numOfParsedBits)
-	numOfParsedBits += 8
+	switch spliceDesc.SpliceDescriptorTag {
+	case 0x00:
+		if spliceDesc.AvailDescriptor != nil {
+			tmpBytes, err = spliceDesc.AvailDescriptor.EncodeToRawBytes()
+		}
+	case 0x01:
+		if spliceDesc.DTMFDescriptor != nil {
+			tmpBytes, err = spliceDesc.DTMFDescriptor.EncodeToRawBytes()
+		}
+	case 0x02:
+		if spliceDesc.SegmentationDescriptor != nil {
+			tmpBytes, err = spliceDesc.SegmentationDescriptor.EncodeToRawBytes()
+		}
+	}
+	if err != nil {
+		return nil, errors.New("Unable To Encode Splice Descriptor(tag: " + strconv.Itoa(int(spliceDesc.SpliceDescriptorTag)) + "): " + err.Error())
+	}
+	if err = spliceDesc.CheckEncodedBody(tmpBytes, "v2013"); err != nil {
+		return nil, err
+	}
+	w.WriteBytes(tmpBytes)
 
-	scte35.EncryptedPacket, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
+	if spliceDesc.PrivateByteInHex != nil {
+		if err = w.WriteHexString(*spliceDesc.PrivateByteInHex); err != nil {
+			return nil, err
+		}
+	}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 6)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 2)
-	scte35.EncryptionAlgorithm, _, err = bits.Byte(tmpBytes, 0)
-	numOfParsedBits += 6
+	return spliceDesc.encodeWithHeader(w)
+}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 33)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 7)
-	tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-	scte35.PTSAdjustment, _, err = bits.Uint64(tmpBytes, 0)
-	numOfParsedBits += 33
+//encodeWithHeader prepends splice_descriptor_tag, descriptor_length and identifier to the bytes written by w
+func (spliceDesc *SpliceDescriptor) encodeWithHeader(w *common.BitWriter) (output []byte, err error) {
+	tmpBytes, _ := w.Bytes()
+	if len(tmpBytes)+4 > 0xff {
+		return nil, errors.New("Encode Error: The splice descriptor(tag: " + strconv.Itoa(int(spliceDesc.SpliceDescriptorTag)) + ") is longer than 255 bytes")
+	}
+	spliceDesc.DescriptorLength = uint8(len(tmpBytes) + 4) // +4 for identifier
 
-	scte35.CWIndex, _, err = bits.Uint8(input, numOfParsedBits)
-	numOfParsedBits += 8
+	output = append(output, byte(spliceDesc.SpliceDescriptorTag), spliceDesc.DescriptorLength)
+	output = append(output, byte(spliceDesc.Identifier>>24), byte(spliceDesc.Identifier>>16), byte(spliceDesc.Identifier>>8), byte(spliceDesc.Identifier))
+	output = append(output, tmpBytes...)
+	return output, nil
+}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 12)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 4)
-	scte35.Tier, _, err = bits.Uint16(tmpBytes, 0)
-	numOfParsedBits += 12
+//DecodeFromRawBytes parses input []byte to SCTE35 object, CRC_32 is verified and a mismatch is returned as *common.CRC32MismatchError
+func (scte35 *SCTE35) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	return scte35.DecodeFromRawBytesWithOptions(input, common.DecodeOptions{})
+}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 12)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 4)
-	scte35.SpliceCommandLength, _, err = bits.Uint16(tmpBytes, 0)
-	numOfParsedBits += 12
+//DecodeFromRawBytesWithOptions is DecodeFromRawBytes with the behaviour controlled by opts
+func (scte35 *SCTE35) DecodeFromRawBytesWithOptions(input []byte, opts common.DecodeOptions) (numOfParsedBits int, err error) {
+	var problems common.DecodeErrors
+	r := common.NewBitReader(input)
+	if opts.RecordFields {
+		r.Record()
+	}
 
-	scte35.SpliceCommandType, _, err = bits.Byte(input, numOfParsedBits)
-	numOfParsedBits += 8
+	scte35.TableID = r.Uint8("table_id")
+	scte35.SectionSyntaxIndicator = r.Bool("section_syntax_indicator")
+	scte35.PrivateIndicator = r.Bool("private_indicator")
+	r.Reserved(2)
+	scte35.SectionLength = uint16(r.Bits("section_length", 12))
+	scte35.ProtocolVersion = r.Uint8("protocol_version")
+	scte35.EncryptedPacket = r.Bool("encrypted_packet")
+	scte35.EncryptionAlgorithm = byte(r.Bits("encryption_algorithm", 6))
+	scte35.PTSAdjustment = r.Bits("pts_adjustment", 33)
+	scte35.CWIndex = r.Uint8("cw_index")
+	scte35.Tier = uint16(r.Bits("tier", 12))
+	scte35.SpliceCommandLength = uint16(r.Bits("splice_command_length", 12))
+	if scte35.EncryptedPacket && !opts.Plaintext && r.Err() == nil {
+		if decrypted, err := scte35.DecryptSection(input, opts.Keys); err != nil {
+			r.Fail("splice_command_type", err.Error())
+		} else {
+			r.ReplaceInput(decrypted)
+		}
+	}
+	scte35.SpliceCommandType = common.SpliceCommandType(r.Uint8("splice_command_type"))
+	if r.Err() != nil {
+		return scte35.FinishDecode(input, r, problems, opts)
+	}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, int(scte35.SpliceCommandLength*8))
-	numOfCommandBits := 0
+	commandName := scte35.SpliceCommandType.String()
+	if commandName == "reserved" {
+		commandName = "splice_command"
+	}
+	sub := r.Sub(commandName, int(scte35.SpliceCommandLength)*8)
 	switch scte35.SpliceCommandType {
 	case 0x00:
-		spliceNull := &common.SpliceNull{}
-		scte35.SpliceNull = spliceNull
+		scte35.SpliceNull = &common.SpliceNull{}
 	case 0x04:
-		spliceSchedule := &common.SpliceSchedule{}
-		numOfCommandBits, err = spliceSchedule.DecodeFromRawBytes(tmpBytes)
-
-		scte35.SpliceSchedule = spliceSchedule
+		scte35.SpliceSchedule = &common.SpliceSchedule{}
+		scte35.SpliceSchedule.DecodeFromBitReader(sub)
 	case 0x05:
-		spliceInsert := &common.SpliceInsert{}
-		numOfCommandBits, err = spliceInsert.DecodeFromRawBytes(tmpBytes)
-
-		scte35.SpliceInsert = spliceInsert
+		scte35.SpliceInsert = &common.SpliceInsert{}
+		scte35.SpliceInsert.DecodeFromBitReader(sub)
 	case 0x06:
-		timeSignal := &common.TimeSignal{}
-		numOfCommandBits, err = timeSignal.DecodeFromRawBytes(tmpBytes)
-
-		scte35.TimeSignal = timeSignal
+		scte35.TimeSignal = &common.TimeSignal{}
+		scte35.TimeSignal.DecodeFromBitReader(sub)
 	case 0x07:
-		bandwidthReservation := &common.BandwidthReservation{}
-		scte35.BandwidthReservation = bandwidthReservation
+		scte35.BandwidthReservation = &common.BandwidthReservation{}
 	case 0xff:
-		privateCommand := &common.PrivateCommand{}
-		numOfCommandBits, err = privateCommand.DecodeFromRawBytes(tmpBytes)
-
-		scte35.PrivateCommand = privateCommand
+		scte35.PrivateCommand = &common.PrivateCommand{}
+		scte35.PrivateCommand.DecodeFromBitReader(sub)
 	default:
-		return 0, errors.New("Unsupported Splice Command Type: " + strconv.Itoa(int(scte35.SpliceCommandType)))
+		sub.Fail("", "Unsupported Splice Command Type: "+strconv.Itoa(int(scte35.SpliceCommandType)))
 	}
-	if err != nil {
-		return 0, errors.New("Unable To Parse Splice Command: " + hex.EncodeToString(tmpBytes) + "\n" + err.Error())
+	if sub.Err() == nil && sub.Left() != 0 {
+		sub.Fail("", "The number of bits("+strconv.Itoa(sub.Pos())+") used by the splice command is not equal to the expected value: "+strconv.Itoa(int(scte35.SpliceCommandLength)*8))
 	}
-	if int(scte35.SpliceCommandLength*8) != numOfCommandBits {
-		return 0, errors.New("The number of bits(" + string(numOfCommandBits) + ") used by the splice command is not equal to the expected value: " + string(scte35.SpliceCommandLength*8))
+	if sub.Err() != nil && opts.Lenient {
+		problems = append(problems, sub.Err())
+		r.Skip(commandName, int(scte35.SpliceCommandLength)*8)
+	} else {
+		r.Merge(sub)
 	}
-	numOfParsedBits += numOfCommandBits
 
-	scte35.DescriptorLoopLength, _, err = bits.Uint16(input, numOfParsedBits)
-	numOfParsedBits += 16
+	scte35.DescriptorLoopLength = r.Uint16("descriptor_loop_length")
 
-	numOfBitsForDescriptors := int(scte35.DescriptorLoopLength) * 8
-	endBitPos := numOfParsedBits + numOfBitsForDescriptors
-	for numOfParsedBits < endBitPos {
+	loop := r.Sub("splice_descriptors", int(scte35.DescriptorLoopLength)*8)
+	for i := 0; loop.Err() == nil && loop.Left() > 0; i++ {
 		spliceDescriptor := &SpliceDescriptor{}
-		tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-
-		descUsedBits := 0
-		descUsedBits, err = spliceDescriptor.DecodeFromRawBytes(tmpBytes)
-		if err != nil {
-			return 0, err
+		sub := loop.Sub("["+strconv.Itoa(i)+"]", -1)
+		spliceDescriptor.DecodeFromBitReader(sub)
+		if sub.Err() != nil && opts.Lenient {
+			//keep the descriptor as raw bytes and move on to the next one by descriptor_length
+			problems = append(problems, sub.Err())
+			spliceDescriptor = &SpliceDescriptor{}
+			sub = loop.Sub("["+strconv.Itoa(i)+"]", -1)
+			spliceDescriptor.DecodeAsUndecoded(sub, problems[len(problems)-1])
 		}
-		numOfParsedBits += descUsedBits
+		loop.Merge(sub)
 
 		scte35.SpliceDescriptors = append(scte35.SpliceDescriptors, *spliceDescriptor)
 	}
+	if loop.Err() != nil && opts.Lenient && r.Err() == nil {
+		problems = append(problems, loop.Err())
+		r.Skip("splice_descriptors", int(scte35.DescriptorLoopLength)*8)
+	} else {
+		r.Merge(loop)
+	}
 
-	inputBitLen := bits.Len(input)
 	bitRequiredForCRC32 := 32
 	if scte35.EncryptedPacket {
 		bitRequiredForCRC32 += 32
 	}
-	if inputBitLen < numOfParsedBits+bitRequiredForCRC32 {
-		scte35 = &SCTE35{}
-		return 0, errors.New("Parse Error: Not Enough Bits For CRC32 Field, Input Bytes(Hex): " + hex.EncodeToString(input))
+	numOfBitsForStuffing := r.Left() - bitRequiredForCRC32
+	if r.Err() == nil && numOfBitsForStuffing%8 != 0 {
+		r.Fail("alignment_stuffing", "The number of bits left for alignment_stuffing is not divisible by 8")
 	}
-	if (inputBitLen-numOfParsedBits-bitRequiredForCRC32)%8 != 0 {
-		scte35 = &SCTE35{}
-		return 0, errors.New("Parse Error: The number of bits left for alignment_stuffing is not divisible by 8: " + hex.EncodeToString(input))
+	if numOfBitsForStuffing > 0 {
+		alignmentStuffingInHex := r.HexString("alignment_stuffing", numOfBitsForStuffing/8)
+		scte35.AlignmentStuffingInHex = &alignmentStuffingInHex
 	}
 
-	if inputBitLen-numOfParsedBits-bitRequiredForCRC32 > 0 {
-		_, scte35.AlignmentStuffingInHex, err = bits.HexString(input, numOfParsedBits, inputBitLen-numOfParsedBits-bitRequiredForCRC32)
-		numOfParsedBits += (inputBitLen - numOfParsedBits - bitRequiredForCRC32)
+	if scte35.EncryptedPacket {
+		eCRC32InHex := r.HexString("e_crc_32", 4)
+		scte35.ECRC32InHex = &eCRC32InHex
 	}
 
-	if scte35.EncryptedPacket {
-		_, scte35.ECRC32InHex, err = bits.HexString(input, numOfParsedBits, 32)
-		numOfParsedBits += 32
+	scte35.CRC32InHex = r.HexString("crc_32", 4)
+
+	return scte35.FinishDecode(input, r, problems, opts)
+}
+
+//EncodeToRawBytes serializes the SCTE35 object to []byte, CRC_32(and E_CRC_32 of encrypted packet) is calculated
+//splice_command_length, descriptor_loop_length, section_length and the descriptor_length of every descriptor are recalculated
+func (scte35 *SCTE35) EncodeToRawBytes() (output []byte, err error) {
+	return scte35.EncodeToRawBytesWithOptions(common.EncodeOptions{})
+}
+
+//EncodeToRawBytesWithOptions is EncodeToRawBytes with the behaviour controlled by opts
+func (scte35 *SCTE35) EncodeToRawBytesWithOptions(opts common.EncodeOptions) (output []byte, err error) {
+	var commandBytes []byte
+	var descriptorBytes []byte
+	var tmpBytes []byte
+
+	switch scte35.SpliceCommandType {
+	case 0x00:
+		commandBytes = []byte{}
+	case 0x04:
+		if scte35.SpliceSchedule == nil {
+			return nil, common.MissingFieldError("splice_schedule")
+		}
+		commandBytes, err = scte35.SpliceSchedule.EncodeToRawBytes()
+	case 0x05:
+		if scte35.SpliceInsert == nil {
+			return nil, common.MissingFieldError("splice_insert")
+		}
+		commandBytes, err = scte35.SpliceInsert.EncodeToRawBytes()
+	case 0x06:
+		if scte35.TimeSignal == nil {
+			return nil, common.MissingFieldError("time_signal")
+		}
+		commandBytes, err = scte35.TimeSignal.EncodeToRawBytes()
+	case 0x07:
+		commandBytes = []byte{}
+	case 0xff:
+		if scte35.PrivateCommand == nil {
+			return nil, common.MissingFieldError("private_command")
+		}
+		commandBytes, err = scte35.PrivateCommand.EncodeToRawBytes()
+	default:
+		return nil, errors.New("Unsupported Splice Command Type: " + strconv.Itoa(int(scte35.SpliceCommandType)))
+	}
+	if err != nil {
+		return nil, errors.New("Unable To Encode Splice Command: " + err.Error())
+	}
+
+	for i := range scte35.SpliceDescriptors {
+		if tmpBytes, err = scte35.SpliceDescriptors[i].EncodeToRawBytes(); err != nil {
+			return nil, err
+		}
+		descriptorBytes = append(descriptorBytes, tmpBytes...)
+	}
+
+	stuffing := &common.BitWriter{}
+	if scte35.AlignmentStuffingInHex != nil {
+		if err = stuffing.WriteHexString(*scte35.AlignmentStuffingInHex); err != nil {
+			return nil, err
+		}
+	}
+	stuffingBytes, _ := stuffing.Bytes()
+	encrypt := scte35.EncryptedPacket && opts.Keys != nil
+	if encrypt {
+		stuffingBytes = common.EncryptionStuffing(stuffingBytes, 1+len(commandBytes)+2+len(descriptorBytes)+4) // splice_command_type, descriptor_loop_length and E_CRC_32 are encrypted as well
+		scte35.AlignmentStuffingInHex = nil
+		if len(stuffingBytes) > 0 {
+			alignmentStuffingInHex := hex.EncodeToString(stuffingBytes)
+			scte35.AlignmentStuffingInHex = &alignmentStuffingInHex
+		}
 	}
 
-	scte35.CRC32InHex, _, err = bits.HexString(input, numOfParsedBits, 32)
-	numOfParsedBits += 32
+	if len(commandBytes) > 0xfff {
+		return nil, errors.New("Encode Error: The splice command is longer than 4095 bytes")
+	}
+	scte35.SpliceCommandLength = uint16(len(commandBytes))
+	scte35.DescriptorLoopLength = uint16(len(descriptorBytes))
+
+	sectionLength := 11 + len(commandBytes) + 2 + len(descriptorBytes) + len(stuffingBytes) + 4 // 11 bytes from protocol_version to splice_command_type, 4 bytes for CRC_32
+	if scte35.EncryptedPacket {
+		sectionLength += 4 // E_CRC_32
+	}
+	if sectionLength > 0xfff {
+		return nil, errors.New("Encode Error: section_length(" + strconv.Itoa(sectionLength) + ") exceeds 4095 bytes")
+	}
+	scte35.SectionLength = uint16(sectionLength)
+
+	w := &common.BitWriter{}
+	w.WriteBits(uint64(scte35.TableID), 8)
+	w.WriteBool(scte35.SectionSyntaxIndicator)
+	w.WriteBool(scte35.PrivateIndicator)
+	w.WriteReserved(2)
+	w.WriteBits(uint64(scte35.SectionLength), 12)
+	w.WriteBits(uint64(scte35.ProtocolVersion), 8)
+	w.WriteBool(scte35.EncryptedPacket)
+	w.WriteBits(uint64(scte35.EncryptionAlgorithm), 6)
+	w.WriteBits(scte35.PTSAdjustment, 33)
+	w.WriteBits(uint64(scte35.CWIndex), 8)
+	w.WriteBits(uint64(scte35.Tier), 12)
+	w.WriteBits(uint64(scte35.SpliceCommandLength), 12)
+	w.WriteBits(uint64(scte35.SpliceCommandType), 8)
+	w.WriteBytes(commandBytes)
+	w.WriteBits(uint64(scte35.DescriptorLoopLength), 16)
+	w.WriteBytes(descriptorBytes)
+	w.WriteBytes(stuffingBytes)
+
+	//E_CRC_32 covers the portion to be encrypted, i.e. from splice_command_type to alignment_stuffing
+	if scte35.EncryptedPacket {
+		tmpBytes, _ = w.Bytes()
+		eCRC32InHex := common.CRC32MPEG2InHex(tmpBytes[13:])
+		scte35.ECRC32InHex = &eCRC32InHex
+		w.WriteHexString(eCRC32InHex)
+	}
 
-	if numOfParsedBits != bits.Len(input) {
-		scte35 = &SCTE35{}
-		return 0, errors.New("Parse Error: The number of used bits for constructing the SCTE35 is less than the input")
+	tmpBytes, _ = w.Bytes()
+	if encrypt {
+		if tmpBytes, err = scte35.EncryptSection(tmpBytes, opts.Keys); err != nil {
+			return nil, err
+		}
+		w = &common.BitWriter{}
+		w.WriteBytes(tmpBytes)
 	}
+	scte35.CRC32InHex = common.CRC32MPEG2InHex(tmpBytes)
+	w.WriteHexString(scte35.CRC32InHex)
 
-	return numOfParsedBits, nil
+	return w.Bytes()
 }
 
 func (scte35 *SCTE35) UnmarshalJSON(bytes []byte) (err error) {
@@ -237,7 +392,11 @@ func (scte35 *SCTE35) UnmarshalJSON(bytes []byte) (err error) {
 	}
 	err = json.Unmarshal(bytes, &aux)
 
-	scte35 = (*SCTE35)(unsafe.Pointer(aux))
+	for _, spliceDesc := range scte35.SpliceDescriptors {
+		if spliceDesc.SegmentationDescriptor != nil {
+			spliceDesc.SegmentationDescriptor.DecodeSegmentationUpid()
+		}
+	}
 	return err
 }
 
@@ -262,6 +421,194 @@ func (scte35 *SCTE35) JSON(indent ...string) (result string) {
 	return string(buf)
 }
 
+//XML serializes SCTE35 object to the SpliceInfoSection of the XML schema of SCTE35(http://www.scte.org/schemas/35)
+//Descriptors without an XML element(e.g. private or undecoded descriptors) and private bytes of descriptors are not written
+func (scte35 *SCTE35) XML(indent ...string) (result string) {
+	result, err := common.XMLString(scte35.EncodeToXML(), indent...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+//EncodeToXML converts SCTE35 object to its SpliceInfoSection element
+func (scte35 *SCTE35) EncodeToXML() *common.XMLSpliceInfoSection {
+	section := scte35.SCTE35.EncodeToXML()
+
+	switch {
+	case scte35.SpliceCommandType == 0x00:
+		section.SpliceNull = &common.XMLEmpty{}
+	case scte35.SpliceCommandType == 0x04 && scte35.SpliceSchedule != nil:
+		section.SpliceSchedule = scte35.SpliceSchedule.EncodeToXML()
+	case scte35.SpliceCommandType == 0x05 && scte35.SpliceInsert != nil:
+		section.SpliceInsert = scte35.SpliceInsert.EncodeToXML()
+	case scte35.SpliceCommandType == 0x06 && scte35.TimeSignal != nil:
+		section.TimeSignal = scte35.TimeSignal.EncodeToXML()
+	case scte35.SpliceCommandType == 0x07:
+		section.BandwidthReservation = &common.XMLEmpty{}
+	case scte35.SpliceCommandType == 0xff && scte35.PrivateCommand != nil:
+		section.PrivateCommand = scte35.PrivateCommand.EncodeToXML()
+	}
+
+	for i := range scte35.SpliceDescriptors {
+		if x := scte35.SpliceDescriptors[i].EncodeToXML(); x != nil {
+			section.SpliceDescriptors = append(section.SpliceDescriptors, *x)
+		}
+	}
+	return section
+}
+
+//DecodeFromXML parses the SpliceInfoSection of the XML schema of SCTE35 to SCTE35 object
+//The section is encoded and decoded again, so that the length fields, CRC_32 and the typed segmentation_upid are set as DecodeFromRawBytes does
+func (scte35 *SCTE35) DecodeFromXML(xmlStr string) (err error) {
+	section, err := common.ParseXMLSpliceInfoSection(xmlStr)
+	if err != nil {
+		return err
+	}
+
+	decoded := &SCTE35{}
+	if err = decoded.SCTE35.DecodeFromXML(section); err != nil {
+		return err
+	}
+	switch decoded.SpliceCommandType {
+	case 0x00:
+		decoded.SpliceNull = &common.SpliceNull{}
+	case 0x04:
+		decoded.SpliceSchedule = &common.SpliceSchedule{}
+		err = decoded.SpliceSchedule.DecodeFromXML(section.SpliceSchedule)
+	case 0x05:
+		decoded.SpliceInsert = &common.SpliceInsert{}
+		err = decoded.SpliceInsert.DecodeFromXML(section.SpliceInsert)
+	case 0x06:
+		decoded.TimeSignal = &common.TimeSignal{}
+		err = decoded.TimeSignal.DecodeFromXML(section.TimeSignal)
+	case 0x07:
+		decoded.BandwidthReservation = &common.BandwidthReservation{}
+	case 0xff:
+		decoded.PrivateCommand = &common.PrivateCommand{}
+		err = decoded.PrivateCommand.DecodeFromXML(section.PrivateCommand)
+	}
+	if err != nil {
+		return err
+	}
+
+	decoded.SpliceDescriptors = []SpliceDescriptor{}
+	for i := range section.SpliceDescriptors {
+		spliceDesc := SpliceDescriptor{}
+		if err = spliceDesc.DecodeFromXML(&section.SpliceDescriptors[i]); err != nil {
+			return errors.New(err.Error() + " (splice descriptor " + strconv.Itoa(i) + ")")
+		}
+		decoded.SpliceDescriptors = append(decoded.SpliceDescriptors, spliceDesc)
+	}
+
+	output, err := decoded.EncodeToRawBytes()
+	if err != nil {
+		return err
+	}
+	*scte35 = SCTE35{}
+	_, err = scte35.DecodeFromRawBytesWithOptions(output, common.DecodeOptions{Plaintext: true})
+	return err
+}
+
+//EncodeToXML converts SpliceDescriptor object to its XML element, nil is returned if the descriptor has no XML element
+func (spliceDesc *SpliceDescriptor) EncodeToXML() *common.XMLSpliceDescriptor {
+	if spliceDesc.UndecodedBytesInHex != nil {
+		return nil
+	}
+	switch {
+	case spliceDesc.SpliceDescriptorTag == 0x00 && spliceDesc.AvailDescriptor != nil:
+		return spliceDesc.AvailDescriptor.EncodeToXML()
+	case spliceDesc.SpliceDescriptorTag == 0x01 && spliceDesc.DTMFDescriptor != nil:
+		return spliceDesc.DTMFDescriptor.EncodeToXML()
+	case spliceDesc.SpliceDescriptorTag == 0x02 && spliceDesc.SegmentationDescriptor != nil:
+		return spliceDesc.SegmentationDescriptor.EncodeToXML()
+	}
+	return nil
+}
+
+//DecodeFromXML sets SpliceDescriptor object from its XML element, identifier is set to "CUEI"
+func (spliceDesc *SpliceDescriptor) DecodeFromXML(x *common.XMLSpliceDescriptor) (err error) {
+	*spliceDesc = SpliceDescriptor{}
+	spliceDesc.Identifier = common.CUEIIdentifier
+
+	switch x.XMLName.Local {
+	case "AvailDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x00
+		spliceDesc.AvailDescriptor = &common.AvailDescriptor{}
+		return spliceDesc.AvailDescriptor.DecodeFromXML(x)
+	case "DTMFDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x01
+		spliceDesc.DTMFDescriptor = &common.DTMFDescriptor{}
+		return spliceDesc.DTMFDescriptor.DecodeFromXML(x)
+	case "SegmentationDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x02
+		spliceDesc.SegmentationDescriptor = &common.SegmentationDescriptor{}
+		return spliceDesc.SegmentationDescriptor.DecodeFromXML(x)
+	}
+	return errors.New("Parse Error: unsupported element " + x.XMLName.Local + " in SpliceInfoSection")
+}
+
+//Validate reports the spec violations of the section as a list of findings
+//Reserved bits are only checked if the object is decoded by DecodeFromRawBytes
+func (scte35 *SCTE35) Validate() (findings []common.Finding) {
+	findings = append(findings, common.ValidateSection(&scte35.SCTE35)...)
+
+	if scte35.SpliceInsert != nil {
+		findings = append(findings, common.ValidateSpliceInsert(scte35.SpliceInsert, "splice_insert")...)
+	}
+
+	for i := range scte35.SpliceDescriptors {
+		spliceDesc := &scte35.SpliceDescriptors[i]
+		path := "splice_descriptors[" + strconv.Itoa(i) + "]"
+
+		findings = append(findings, common.ValidateSpliceDescriptor(&spliceDesc.SpliceDescriptor, path)...)
+		if spliceDesc.DTMFDescriptor != nil {
+			findings = append(findings, common.ValidateDTMFDescriptor(spliceDesc.DTMFDescriptor, path+".dtmf_descriptor")...)
+		}
+		if spliceDesc.SegmentationDescriptor != nil {
+			findings = append(findings, common.ValidateSegmentationDescriptor(spliceDesc.SegmentationDescriptor, path+".segmentation_descriptor")...)
+		}
+	}
+
+	if raw := scte35.RawBytes(); raw != nil {
+		//encode a deep copy made through JSON, so that the length fields and CRC_32 of the object and its descriptors are kept
+		copied := &SCTE35{}
+		var encoded []byte
+		err := copied.DecodeFromJSON(scte35.JSON())
+		if err == nil {
+			encoded, err = copied.EncodeToRawBytes()
+		}
+		if err != nil {
+			findings = append(findings, common.Finding{Severity: common.SeverityError, Path: "", Message: "unable to re-encode the section: " + err.Error()})
+		} else {
+			findings = append(findings, common.ValidateReservedBits(raw, encoded, scte35.EncryptedPacket)...)
+		}
+	}
+
+	return findings
+}
+
+//SplicePTS returns the effective splice time of time_signal or program splice mode splice_insert, with pts_adjustment applied
+//ok is false if the command carries no splice time, e.g. splice_immediate_flag is set
+func (scte35 *SCTE35) SplicePTS() (pts uint64, ok bool) {
+	switch {
+	case scte35.TimeSignal != nil:
+		return scte35.TimeSignal.SpliceTime.EffectivePTS(scte35.PTSAdjustment)
+	case scte35.SpliceInsert != nil:
+		return scte35.SpliceInsert.SpliceTime.EffectivePTS(scte35.PTSAdjustment)
+	}
+	return 0, false
+}
+
+//JSONWithNames is JSON with the names of enum fields(e.g. "splice_command_type_name": "time_signal") added next to their numeric values
+func (scte35 *SCTE35) JSONWithNames(indent ...string) (result string) {
+	result, err := common.IndentedJSON(scte35, true, indent...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
 func (scte35 *SCTE35) SchemaVersion() string {
 	return "v2013"
 }
@@ -0,0 +1,392 @@
+package schema_2017
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//Builder authors a splice_info_section, mandatory fields are filled with their default values,
+//all length fields and CRC_32 are calculated by Build()
+//e.g. NewTimeSignal(pts).WithSegmentation(NewSegmentation(1, 0x34).WithDuration(30 * time.Second)).Build()
+type Builder struct {
+	scte35  *SCTE35
+	errMsgs []string
+}
+
+func newBuilder(spliceCommandType common.SpliceCommandType) *Builder {
+	scte35 := &SCTE35{}
+	scte35.TableID = 0xFC
+	scte35.Tier = 0xFFF
+	scte35.SpliceCommandType = spliceCommandType
+	scte35.SpliceDescriptors = []SpliceDescriptor{}
+
+	return &Builder{scte35: scte35}
+}
+
+func (b *Builder) fail(errMsg string) *Builder {
+	b.errMsgs = append(b.errMsgs, errMsg)
+	return b
+}
+
+//NewSpliceNull starts a splice_null section
+func NewSpliceNull() *Builder {
+	b := newBuilder(0x00)
+	b.scte35.SpliceNull = &common.SpliceNull{}
+	return b
+}
+
+//NewTimeSignal starts a time_signal section splicing at pts(90 kHz ticks)
+func NewTimeSignal(pts uint64) *Builder {
+	b := newBuilder(0x06)
+	if pts >= common.PTSModulus {
+		b.fail("pts_time(" + strconv.FormatUint(pts, 10) + ") exceeds 33 bits")
+	}
+	b.scte35.TimeSignal = &common.TimeSignal{SpliceTime: &common.SpliceTime{TimeSpecifiedFlag: true, PTSTime: &pts}}
+	return b
+}
+
+//NewImmediateTimeSignal starts a time_signal section without splice time, i.e. time_specified_flag is 0
+func NewImmediateTimeSignal() *Builder {
+	b := newBuilder(0x06)
+	b.scte35.TimeSignal = &common.TimeSignal{SpliceTime: &common.SpliceTime{TimeSpecifiedFlag: false}}
+	return b
+}
+
+//NewSpliceInsert starts a program splice mode splice_insert section, the splice is immediate unless WithSpliceTime is called
+func NewSpliceInsert(spliceEventID uint32, outOfNetwork bool) *Builder {
+	b := newBuilder(0x05)
+
+	programSpliceFlag := true
+	durationFlag := false
+	spliceImmediateFlag := true
+	uniqueProgramID := uint16(0)
+	availNum := byte(0)
+	availsExpected := byte(0)
+	b.scte35.SpliceInsert = &common.SpliceInsert{
+		SpliceEventID:         spliceEventID,
+		OutOfNetworkIndicator: &outOfNetwork,
+		ProgramSpliceFlag:     &programSpliceFlag,
+		DurationFlag:          &durationFlag,
+		SpliceImmediateFlag:   &spliceImmediateFlag,
+		UniqueProgramID:       &uniqueProgramID,
+		AvailNum:              &availNum,
+		AvailsExpected:        &availsExpected,
+	}
+	return b
+}
+
+//NewSpliceInsertCancel starts a splice_insert section cancelling spliceEventID
+func NewSpliceInsertCancel(spliceEventID uint32) *Builder {
+	b := newBuilder(0x05)
+	b.scte35.SpliceInsert = &common.SpliceInsert{SpliceEventID: spliceEventID, SpliceEventCancelIndicator: true}
+	return b
+}
+
+//WithPTSAdjustment sets pts_adjustment(90 kHz ticks)
+func (b *Builder) WithPTSAdjustment(ptsAdjustment uint64) *Builder {
+	if ptsAdjustment >= common.PTSModulus {
+		return b.fail("pts_adjustment(" + strconv.FormatUint(ptsAdjustment, 10) + ") exceeds 33 bits")
+	}
+	b.scte35.PTSAdjustment = ptsAdjustment
+	return b
+}
+
+//WithTier sets tier, which is 0xFFF by default
+func (b *Builder) WithTier(tier uint16) *Builder {
+	if tier > 0xFFF {
+		return b.fail("tier(" + strconv.Itoa(int(tier)) + ") exceeds 12 bits")
+	}
+	b.scte35.Tier = tier
+	return b
+}
+
+func (b *Builder) activeSpliceInsert(fieldName string) *common.SpliceInsert {
+	if b.scte35.SpliceInsert == nil {
+		b.fail(fieldName + " is only available for splice_insert")
+		return nil
+	}
+	if b.scte35.SpliceInsert.SpliceEventCancelIndicator {
+		b.fail(fieldName + " is not available when splice_event_cancel_indicator is set")
+		return nil
+	}
+	return b.scte35.SpliceInsert
+}
+
+//WithSpliceTime sets the splice time(90 kHz ticks) of splice_insert, which clears splice_immediate_flag
+func (b *Builder) WithSpliceTime(pts uint64) *Builder {
+	spliceInsert := b.activeSpliceInsert("splice_time")
+	if spliceInsert == nil {
+		return b
+	}
+	if pts >= common.PTSModulus {
+		return b.fail("pts_time(" + strconv.FormatUint(pts, 10) + ") exceeds 33 bits")
+	}
+
+	spliceImmediateFlag := false
+	spliceInsert.SpliceImmediateFlag = &spliceImmediateFlag
+	spliceInsert.SpliceTime = &common.SpliceTime{TimeSpecifiedFlag: true, PTSTime: &pts}
+	return b
+}
+
+//WithBreakDuration sets break_duration of splice_insert
+func (b *Builder) WithBreakDuration(duration time.Duration, autoReturn bool) *Builder {
+	spliceInsert := b.activeSpliceInsert("break_duration")
+	if spliceInsert == nil {
+		return b
+	}
+	ticks := common.DurationToTicks(duration)
+	if ticks >= common.PTSModulus {
+		return b.fail("break_duration(" + duration.String() + ") exceeds 33 bits")
+	}
+
+	durationFlag := true
+	spliceInsert.DurationFlag = &durationFlag
+	spliceInsert.BreakDuration = &common.BreakDuration{AutoReturn: autoReturn, Duration: ticks}
+	return b
+}
+
+//WithAvail sets unique_program_id, avail_num and avails_expected of splice_insert
+func (b *Builder) WithAvail(uniqueProgramID uint16, availNum byte, availsExpected byte) *Builder {
+	spliceInsert := b.activeSpliceInsert("avail_num")
+	if spliceInsert == nil {
+		return b
+	}
+	if availsExpected != 0 && availNum > availsExpected {
+		return b.fail("avail_num(" + strconv.Itoa(int(availNum)) + ") is more than avails_expected(" + strconv.Itoa(int(availsExpected)) + ")")
+	}
+
+	spliceInsert.UniqueProgramID = &uniqueProgramID
+	spliceInsert.AvailNum = &availNum
+	spliceInsert.AvailsExpected = &availsExpected
+	return b
+}
+
+func (b *Builder) addDescriptor(tag common.SpliceDescriptorTag) *SpliceDescriptor {
+	spliceDesc := SpliceDescriptor{}
+	spliceDesc.SpliceDescriptorTag = tag
+	spliceDesc.Identifier = common.CUEIIdentifier
+	b.scte35.SpliceDescriptors = append(b.scte35.SpliceDescriptors, spliceDesc)
+	return &b.scte35.SpliceDescriptors[len(b.scte35.SpliceDescriptors)-1]
+}
+
+//WithAvailDescriptor appends an avail_descriptor
+func (b *Builder) WithAvailDescriptor(providerAvailID uint32) *Builder {
+	b.addDescriptor(0x00).AvailDescriptor = &common.AvailDescriptor{ProviderAvailID: providerAvailID}
+	return b
+}
+
+//WithDTMFDescriptor appends a DTMF_descriptor, preroll is in 1/10 second
+func (b *Builder) WithDTMFDescriptor(preroll byte, dtmfChars string) *Builder {
+	if len(dtmfChars) > 7 {
+		return b.fail("dtmf_chars(" + dtmfChars + ") is longer than 7 characters")
+	}
+	if strings.Trim(dtmfChars, "0123456789*#") != "" {
+		return b.fail("dtmf_chars(" + dtmfChars + ") contains characters other than 0-9, * and #")
+	}
+	b.addDescriptor(0x01).DTMFDescriptor = &common.DTMFDescriptor{Preroll: preroll, DTMFChars: dtmfChars}
+	return b
+}
+
+//WithTimeDescriptor appends a time_descriptor
+func (b *Builder) WithTimeDescriptor(taiSeconds uint64, taiNs uint32, utcOffset uint16) *Builder {
+	if taiSeconds >= 1<<48 {
+		return b.fail("TAI_seconds exceeds 48 bits")
+	}
+	b.addDescriptor(0x03).TimeDescriptor = &common.TimeDescriptor{TAI_seconds: taiSeconds, TAI_ns: taiNs, UTC_offset: utcOffset}
+	return b
+}
+
+//WithSegmentation appends a segmentation_descriptor
+func (b *Builder) WithSegmentation(seg *SegmentationBuilder) *Builder {
+	segDesc, err := seg.build()
+	if err != nil {
+		return b.fail(err.Error())
+	}
+	b.addDescriptor(0x02).SegmentationDescriptor = segDesc
+	return b
+}
+
+//Build validates the section and calculates all length fields and CRC_32
+func (b *Builder) Build() (*SCTE35, error) {
+	if len(b.errMsgs) > 0 {
+		return nil, errors.New("Build Error: " + strings.Join(b.errMsgs, "; "))
+	}
+
+	if _, err := b.scte35.EncodeToRawBytes(); err != nil {
+		return nil, errors.New("Build Error: " + err.Error())
+	}
+	return b.scte35, nil
+}
+
+//SegmentationBuilder authors a segmentation_descriptor for Builder.WithSegmentation
+//By default it applies to the whole program, delivery is not restricted and segmentation_upid_type is 0x00(Not Used)
+type SegmentationBuilder struct {
+	segDesc *SegmentationDescriptor
+	errMsgs []string
+}
+
+func (s *SegmentationBuilder) fail(errMsg string) *SegmentationBuilder {
+	s.errMsgs = append(s.errMsgs, errMsg)
+	return s
+}
+
+//NewSegmentation starts a segmentation_descriptor of segmentationTypeID
+func NewSegmentation(segmentationEventID uint32, segmentationTypeID common.SegmentationTypeID) *SegmentationBuilder {
+	programSegmentationFlag := true
+	segmentationDurationFlag := false
+	deliveryNotRestrictedFlag := true
+	upidType := common.SegmentationUpidType(0x00)
+	segmentNum := uint8(0)
+	segmentsExpected := uint8(0)
+
+	segDesc := &SegmentationDescriptor{}
+	segDesc.SegmentationEventID = segmentationEventID
+	segDesc.ProgramSegmentationFlag = &programSegmentationFlag
+	segDesc.SegmentationDurationFlag = &segmentationDurationFlag
+	segDesc.DeliveryNotRestrictedFlag = &deliveryNotRestrictedFlag
+	segDesc.SegmentationUpidType = &upidType
+	segDesc.SegmentationTypeID = &segmentationTypeID
+	segDesc.SegmentNum = &segmentNum
+	segDesc.SegmentsExpected = &segmentsExpected
+
+	s := &SegmentationBuilder{segDesc: segDesc}
+	if segmentationTypeID == 0x34 || segmentationTypeID == 0x36 {
+		subSegmentNum := uint8(0)
+		subSegmentsExpected := uint8(0)
+		segDesc.SubSegmentNum = &subSegmentNum
+		segDesc.SubSegmentsExpected = &subSegmentsExpected
+	}
+	return s
+}
+
+//NewSegmentationCancel starts a segmentation_descriptor cancelling segmentationEventID
+func NewSegmentationCancel(segmentationEventID uint32) *SegmentationBuilder {
+	segDesc := &SegmentationDescriptor{}
+	segDesc.SegmentationEventID = segmentationEventID
+	segDesc.SegmentationEventCancelIndicator = true
+	return &SegmentationBuilder{segDesc: segDesc}
+}
+
+func (s *SegmentationBuilder) active(fieldName string) bool {
+	if s.segDesc.SegmentationEventCancelIndicator {
+		s.fail(fieldName + " is not available when segmentation_event_cancel_indicator is set")
+		return false
+	}
+	return true
+}
+
+//WithDuration sets segmentation_duration
+func (s *SegmentationBuilder) WithDuration(duration time.Duration) *SegmentationBuilder {
+	if !s.active("segmentation_duration") {
+		return s
+	}
+	ticks := common.DurationToTicks(duration)
+	if ticks >= 1<<40 {
+		return s.fail("segmentation_duration(" + duration.String() + ") exceeds 40 bits")
+	}
+
+	segmentationDurationFlag := true
+	s.segDesc.SegmentationDurationFlag = &segmentationDurationFlag
+	s.segDesc.SegmentationDuration = &ticks
+	return s
+}
+
+//WithUpid sets segmentation_upid_type and segmentation_upid from the typed upid
+func (s *SegmentationBuilder) WithUpid(upid common.Upid) *SegmentationBuilder {
+	if !s.active("segmentation_upid") {
+		return s
+	}
+	upidBytes, err := upid.EncodeToRawBytes()
+	if err != nil {
+		return s.fail(err.Error())
+	}
+	if len(upidBytes) > 0xff {
+		return s.fail("segmentation_upid is longer than 255 bytes")
+	}
+
+	upidType := upid.UpidType()
+	upidInHex := hex.EncodeToString(upidBytes)
+	s.segDesc.SegmentationUpidType = &upidType
+	s.segDesc.SegmentationUpidInHex = &upidInHex
+	s.segDesc.SegmentationUpid = &common.SegmentationUpid{Upid: upid}
+	return s
+}
+
+//WithSegmentNum sets segment_num and segments_expected
+func (s *SegmentationBuilder) WithSegmentNum(segmentNum uint8, segmentsExpected uint8) *SegmentationBuilder {
+	if !s.active("segment_num") {
+		return s
+	}
+	if segmentNum > segmentsExpected {
+		return s.fail("segment_num(" + strconv.Itoa(int(segmentNum)) + ") is more than segments_expected(" + strconv.Itoa(int(segmentsExpected)) + ")")
+	}
+
+	s.segDesc.SegmentNum = &segmentNum
+	s.segDesc.SegmentsExpected = &segmentsExpected
+	return s
+}
+
+//WithSubSegmentNum sets sub_segment_num and sub_segments_expected, which only exist for segmentation_type_id 0x34 and 0x36
+func (s *SegmentationBuilder) WithSubSegmentNum(subSegmentNum uint8, subSegmentsExpected uint8) *SegmentationBuilder {
+	if !s.active("sub_segment_num") {
+		return s
+	}
+	if s.segDesc.SubSegmentNum == nil {
+		return s.fail("sub_segment_num is only available for segmentation_type_id 0x34 and 0x36")
+	}
+	if subSegmentNum > subSegmentsExpected {
+		return s.fail("sub_segment_num(" + strconv.Itoa(int(subSegmentNum)) + ") is more than sub_segments_expected(" + strconv.Itoa(int(subSegmentsExpected)) + ")")
+	}
+
+	s.segDesc.SubSegmentNum = &subSegmentNum
+	s.segDesc.SubSegmentsExpected = &subSegmentsExpected
+	return s
+}
+
+//WithDeliveryRestrictions clears delivery_not_restricted_flag and sets the restriction flags
+func (s *SegmentationBuilder) WithDeliveryRestrictions(webDeliveryAllowed bool, noRegionalBlackout bool, archiveAllowed bool, deviceRestrictions common.DeviceRestrictions) *SegmentationBuilder {
+	if !s.active("delivery restrictions") {
+		return s
+	}
+	if deviceRestrictions > 0x03 {
+		return s.fail("device_restrictions(" + strconv.Itoa(int(deviceRestrictions)) + ") exceeds 2 bits")
+	}
+
+	deliveryNotRestrictedFlag := false
+	s.segDesc.DeliveryNotRestrictedFlag = &deliveryNotRestrictedFlag
+	s.segDesc.WebDeliveryAllowedFlag = &webDeliveryAllowed
+	s.segDesc.NoRegionalBlackoutFlag = &noRegionalBlackout
+	s.segDesc.ArchiveAllowedFlag = &archiveAllowed
+	s.segDesc.DeviceRestrictions = &deviceRestrictions
+	return s
+}
+
+//WithComponent switches the descriptor to component mode and appends a component with its pts_offset(90 kHz ticks)
+func (s *SegmentationBuilder) WithComponent(componentTag byte, ptsOffset uint64) *SegmentationBuilder {
+	if !s.active("segmentation_components") {
+		return s
+	}
+	if ptsOffset >= common.PTSModulus {
+		return s.fail("pts_offset(" + strconv.FormatUint(ptsOffset, 10) + ") exceeds 33 bits")
+	}
+
+	programSegmentationFlag := false
+	s.segDesc.ProgramSegmentationFlag = &programSegmentationFlag
+	if s.segDesc.SegmentationComponents == nil {
+		s.segDesc.SegmentationComponents = &[]common.SegmentationComponent{}
+	}
+	*s.segDesc.SegmentationComponents = append(*s.segDesc.SegmentationComponents, common.SegmentationComponent{ComponentTag: componentTag, PTSOffset: ptsOffset})
+	return s
+}
+
+func (s *SegmentationBuilder) build() (*SegmentationDescriptor, error) {
+	if len(s.errMsgs) > 0 {
+		return nil, errors.New(strings.Join(s.errMsgs, "; "))
+	}
+	return s.segDesc, nil
+}
@@ -1,7 +1,6 @@
 package schema_2017
 
 import (
-	bits "github.com/chanyk-joseph/gobits"
 	common "github.com/chanyk-joseph/scte35_decoder/common"
 )
 
@@ -13,103 +12,70 @@ type SegmentationDescriptor struct {
 }
 
 func (segDesc *SegmentationDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
-
-	segDesc.SegmentationEventID, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
-
-	segDesc.SegmentationEventCancelIndicator, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
-
-	numOfParsedBits += 7 //reserved 7 bits
-
-	if !segDesc.SegmentationEventCancelIndicator {
-		_, segDesc.ProgramSegmentationFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
-
-		_, segDesc.SegmentationDurationFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+	r := common.NewBitReader(input)
+	segDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-		_, segDesc.DeliveryNotRestrictedFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+//DecodeFromBitReader parses SegmentationDescriptor object from r, sub_segment_num and sub_segments_expected follow the 2013 fields
+func (segDesc *SegmentationDescriptor) DecodeFromBitReader(r *common.BitReader) {
+	segDesc.SegmentationDescriptor.DecodeFromBitReader(r)
 
-		if !*segDesc.DeliveryNotRestrictedFlag {
-			_, segDesc.WebDeliveryAllowedFlag, err = bits.Bool(input, numOfParsedBits)
-			numOfParsedBits++
+	if !segDesc.SegmentationEventCancelIndicator && (*segDesc.SegmentationTypeID == 0x34 || *segDesc.SegmentationTypeID == 0x36) {
+		subSegmentNum := r.Uint8("sub_segment_num")
+		segDesc.SubSegmentNum = &subSegmentNum
 
-			_, segDesc.NoRegionalBlackoutFlag, err = bits.Bool(input, numOfParsedBits)
-			numOfParsedBits++
+		subSegmentsExpected := r.Uint8("sub_segments_expected")
+		segDesc.SubSegmentsExpected = &subSegmentsExpected
+	}
+}
 
-			_, segDesc.ArchiveAllowedFlag, err = bits.Bool(input, numOfParsedBits)
-			numOfParsedBits++
+//EncodeToRawBytes serializes SegmentationDescriptor object to []byte
+func (segDesc *SegmentationDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	output, err = segDesc.SegmentationDescriptor.EncodeToRawBytes()
+	if err != nil {
+		return nil, err
+	}
 
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 2)
-			tmpBytes, _ = bits.ShiftRight(tmpBytes, 6)
-			_, segDesc.DeviceRestrictions, err = bits.Uint8(tmpBytes, 0)
-			numOfParsedBits += 2
-		} else {
-			numOfParsedBits += 5
+	if !segDesc.SegmentationEventCancelIndicator && (*segDesc.SegmentationTypeID == 0x34 || *segDesc.SegmentationTypeID == 0x36) {
+		if segDesc.SubSegmentNum == nil {
+			return nil, common.MissingFieldError("sub_segment_num")
 		}
-
-		if !*segDesc.ProgramSegmentationFlag {
-			_, segDesc.ComponentCount, err = bits.Uint8(input, numOfParsedBits)
-			numOfParsedBits += 8
-
-			var components []common.SegmentationComponent
-			for i := 0; i < int(*segDesc.ComponentCount); i++ {
-				segComp := common.SegmentationComponent{}
-
-				segComp.ComponentTag, _, err = bits.Byte(input, numOfParsedBits)
-				numOfParsedBits += 8
-
-				numOfParsedBits += 7 //reserved 7 bits
-
-				tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 33)
-				tmpBytes, _ = bits.ShiftRight(tmpBytes, 7)
-				tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-				segComp.PTSOffset, _, err = bits.Uint64(tmpBytes, 0)
-				numOfParsedBits += 33
-
-				components = append(components, segComp)
-			}
-			segDesc.SegmentationComponents = &components
+		if segDesc.SubSegmentsExpected == nil {
+			return nil, common.MissingFieldError("sub_segments_expected")
 		}
+		output = append(output, *segDesc.SubSegmentNum, *segDesc.SubSegmentsExpected)
+	}
 
-		if *segDesc.SegmentationDurationFlag {
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 40)
-			tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-			_, segDesc.SegmentationDuration, err = bits.Uint64(tmpBytes, 0)
-			numOfParsedBits += 40
-		}
+	return output, nil
+}
 
-		_, segDesc.SegmentationUpidType, err = bits.Byte(input, numOfParsedBits)
-		numOfParsedBits += 8
+//EncodeToXML converts SegmentationDescriptor object to its XML element
+func (segDesc *SegmentationDescriptor) EncodeToXML() *common.XMLSpliceDescriptor {
+	x := segDesc.SegmentationDescriptor.EncodeToXML()
+	x.SubSegmentNum = segDesc.SubSegmentNum
+	x.SubSegmentsExpected = segDesc.SubSegmentsExpected
+	return x
+}
 
-		_, segDesc.SegmentationUpidLength, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
+//DecodeFromXML sets SegmentationDescriptor object from its XML element
+//sub_segment_num and sub_segments_expected are 0 if they are absent for segmentation_type_id 0x34/0x36
+func (segDesc *SegmentationDescriptor) DecodeFromXML(x *common.XMLSpliceDescriptor) (err error) {
+	*segDesc = SegmentationDescriptor{}
+	if err = segDesc.SegmentationDescriptor.DecodeFromXML(x); err != nil {
+		return err
+	}
 
-		if int(*segDesc.SegmentationUpidLength) > 0 {
-			_, segDesc.SegmentationUpidInHex, err = bits.HexString(input, numOfParsedBits, int(*segDesc.SegmentationUpidLength)*8)
-			numOfParsedBits += (int(*segDesc.SegmentationUpidLength) * 8)
+	if !segDesc.SegmentationEventCancelIndicator && (*segDesc.SegmentationTypeID == 0x34 || *segDesc.SegmentationTypeID == 0x36) {
+		subSegmentNum, subSegmentsExpected := uint8(0), uint8(0)
+		if x.SubSegmentNum != nil {
+			subSegmentNum = *x.SubSegmentNum
 		}
-
-		_, segDesc.SegmentationTypeID, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
-
-		_, segDesc.SegmentNum, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
-
-		_, segDesc.SegmentsExpected, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
-
-		if *segDesc.SegmentationTypeID == 0x34 || *segDesc.SegmentationTypeID == 0x36 {
-			_, segDesc.SubSegmentNum, err = bits.Uint8(input, numOfParsedBits)
-			numOfParsedBits += 8
-
-			_, segDesc.SubSegmentsExpected, err = bits.Uint8(input, numOfParsedBits)
-			numOfParsedBits += 8
+		if x.SubSegmentsExpected != nil {
+			subSegmentsExpected = *x.SubSegmentsExpected
 		}
+		segDesc.SubSegmentNum = &subSegmentNum
+		segDesc.SubSegmentsExpected = &subSegmentsExpected
 	}
-
-	return numOfParsedBits, err
+	return nil
 }
@@ -0,0 +1,651 @@
+package schema_2022
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//SCTE35(splice_info_section) is implemented based on SCTE35 2022, which is backward compatible with the 2019 and 2020 revisions
+//https://account.scte.org/standards/library/catalog/scte-35-digital-program-insertion-cueing-message/
+type SCTE35 struct {
+	common.SCTE35
+
+	//Available Splice Commands
+	SpliceNull           *common.SpliceNull           `json:"splice_null,omitempty"`
+	SpliceSchedule       *common.SpliceSchedule       `json:"splice_schedule,omitempty"`
+	SpliceInsert         *SpliceInsert                `json:"splice_insert,omitempty"`
+	TimeSignal           *common.TimeSignal           `json:"time_signal,omitempty"`
+	BandwidthReservation *common.BandwidthReservation `json:"bandwidth_reservation,omitempty"`
+	PrivateCommand       *common.PrivateCommand       `json:"private_command,omitempty"`
+
+	SpliceDescriptors []SpliceDescriptor `json:"splice_descriptors"`
+}
+
+type SpliceDescriptor struct {
+	common.SpliceDescriptor
+
+	AvailDescriptor        *common.AvailDescriptor `json:"avail_descriptor,omitempty"`
+	DTMFDescriptor         *common.DTMFDescriptor  `json:"dtmf_descriptor,omitempty"`
+	SegmentationDescriptor *SegmentationDescriptor `json:"segmentation_descriptor,omitempty"`
+	TimeDescriptor         *common.TimeDescriptor  `json:"time_descriptor,omitempty"`
+	AudioDescriptor        *AudioDescriptor        `json:"audio_descriptor,omitempty"`
+}
+
+func (spliceDesc *SpliceDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := common.NewBitReader(input)
+	spliceDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses SpliceDescriptor object from r, the bytes not used by the descriptor of the tag are kept as private bytes
+func (spliceDesc *SpliceDescriptor) DecodeFromBitReader(r *common.BitReader) {
+	spliceDesc.SpliceDescriptorTag = common.SpliceDescriptorTag(r.Uint8("splice_descriptor_tag"))
+	spliceDesc.DescriptorLength = r.Uint8("descriptor_length")
+	if r.Err() == nil && spliceDesc.DescriptorLength < 4 {
+		r.Fail("descriptor_length", "descriptor_length("+strconv.Itoa(int(spliceDesc.DescriptorLength))+") is less than the 4 bytes of identifier")
+		return
+	}
+
+	body := r.Sub("", int(spliceDesc.DescriptorLength)*8)
+	spliceDesc.Identifier = body.Uint32("identifier")
+
+	switch spliceDesc.SpliceDescriptorTag {
+	case 0x00:
+		availDesc := &common.AvailDescriptor{}
+		sub := body.Sub("avail_descriptor", -1)
+		availDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
+
+		spliceDesc.AvailDescriptor = availDesc
+	case 0x01:
+		dtmfDesc := &common.DTMFDescriptor{}
+		sub := body.Sub("dtmf_descriptor", -1)
+		dtmfDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
+
+		spliceDesc.DTMFDescriptor = dtmfDesc
+	case 0x02:
+		segDesc := &SegmentationDescriptor{}
+		sub := body.Sub("segmentation_descriptor", -1)
+		segDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
+
+		spliceDesc.SegmentationDescriptor = segDesc
+	case 0x03:
+		timeDesc := &common.TimeDescriptor{}
+		sub := body.Sub("time_descriptor", -1)
+		timeDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
+
+		spliceDesc.TimeDescriptor = timeDesc
+	case 0x04:
+		audioDesc := &AudioDescriptor{}
+		sub := body.Sub("audio_descriptor", -1)
+		audioDesc.DecodeFromBitReader(sub)
+		body.Merge(sub)
+
+		spliceDesc.AudioDescriptor = audioDesc
+	}
+
+	if body.Err() == nil && body.Left() > 0 {
+		privateByteInHex := body.HexString("private_byte", body.Left()/8)
+		spliceDesc.PrivateByteInHex = &privateByteInHex
+	}
+	r.Merge(body)
+}
+
+//EncodeToRawBytes serializes SpliceDescriptor object to []byte, descriptor_length is recalculated
+func (spliceDesc *SpliceDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	var tmpBytes []byte
+	w := &common.BitWriter{}
+
+	if spliceDesc.UndecodedBytesInHex != nil {
+		if err = w.WriteHexString(*spliceDesc.UndecodedBytesInHex); err != nil {
+			return nil, err
+		}
+		return spliceDesc.encodeWithHeader(w)
+	}
+
+	switch spliceDesc.SpliceDescriptorTag {
+	case 0x00:
+		if spliceDesc.AvailDescriptor != nil {
+			tmpBytes, err = spliceDesc.AvailDescriptor.EncodeToRawBytes()
+		}
+	case 0x01:
+		if spliceDesc.DTMFDescriptor != nil {
+			tmpBytes, err = spliceDesc.DTMFDescriptor.EncodeToRawBytes()
+		}
+	case 0x02:
+		if spliceDesc.SegmentationDescriptor != nil {
+			tmpBytes, err = spliceDesc.SegmentationDescriptor.EncodeToRawBytes()
+		}
+	case 0x03:
+		if spliceDesc.TimeDescriptor != nil {
+			tmpBytes, err = spliceDesc.TimeDescriptor.EncodeToRawBytes()
+		}
+	case 0x04:
+		if spliceDesc.AudioDescriptor != nil {
+			tmpBytes, err = spliceDesc.AudioDescriptor.EncodeToRawBytes()
+		}
+	}
+	if err != nil {
+		return nil, errors.New("Unable To Encode Splice Descriptor(tag: " + strconv.Itoa(int(spliceDesc.SpliceDescriptorTag)) + "): " + err.Error())
+	}
+	if err = spliceDesc.CheckEncodedBody(tmpBytes, "v2022"); err != nil {
+		return nil, err
+	}
+	w.WriteBytes(tmpBytes)
+
+	if spliceDesc.PrivateByteInHex != nil {
+		if err = w.WriteHexString(*spliceDesc.PrivateByteInHex); err != nil {
+			return nil, err
+		}
+	}
+
+	return spliceDesc.encodeWithHeader(w)
+}
+
+//encodeWithHeader prepends splice_descriptor_tag, descriptor_length and identifier to the bytes written by w
+func (spliceDesc *SpliceDescriptor) encodeWithHeader(w *common.BitWriter) (output []byte, err error) {
+	tmpBytes, _ := w.Bytes()
+	if len(tmpBytes)+4 > 0xff {
+		return nil, errors.New("Encode Error: The splice descriptor(tag: " + strconv.Itoa(int(spliceDesc.SpliceDescriptorTag)) + ") is longer than 255 bytes")
+	}
+	spliceDesc.DescriptorLength = uint8(len(tmpBytes) + 4) // +4 for identifier
+
+	output = append(output, byte(spliceDesc.SpliceDescriptorTag), spliceDesc.DescriptorLength)
+	output = append(output, byte(spliceDesc.Identifier>>24), byte(spliceDesc.Identifier>>16), byte(spliceDesc.Identifier>>8), byte(spliceDesc.Identifier))
+	output = append(output, tmpBytes...)
+	return output, nil
+}
+
+//DecodeFromRawBytes parses input []byte to SCTE35 object, CRC_32 is verified and a mismatch is returned as *common.CRC32MismatchError
+func (scte35 *SCTE35) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	return scte35.DecodeFromRawBytesWithOptions(input, common.DecodeOptions{})
+}
+
+//DecodeFromRawBytesWithOptions is DecodeFromRawBytes with the behaviour controlled by opts
+func (scte35 *SCTE35) DecodeFromRawBytesWithOptions(input []byte, opts common.DecodeOptions) (numOfParsedBits int, err error) {
+	var problems common.DecodeErrors
+	r := common.NewBitReader(input)
+	if opts.RecordFields {
+		r.Record()
+	}
+
+	scte35.TableID = r.Uint8("table_id")
+	scte35.SectionSyntaxIndicator = r.Bool("section_syntax_indicator")
+	scte35.PrivateIndicator = r.Bool("private_indicator")
+	r.Reserved(2)
+	scte35.SectionLength = uint16(r.Bits("section_length", 12))
+	scte35.ProtocolVersion = r.Uint8("protocol_version")
+	scte35.EncryptedPacket = r.Bool("encrypted_packet")
+	scte35.EncryptionAlgorithm = byte(r.Bits("encryption_algorithm", 6))
+	scte35.PTSAdjustment = r.Bits("pts_adjustment", 33)
+	scte35.CWIndex = r.Uint8("cw_index")
+	scte35.Tier = uint16(r.Bits("tier", 12))
+	scte35.SpliceCommandLength = uint16(r.Bits("splice_command_length", 12))
+	if scte35.EncryptedPacket && !opts.Plaintext && r.Err() == nil {
+		if decrypted, err := scte35.DecryptSection(input, opts.Keys); err != nil {
+			r.Fail("splice_command_type", err.Error())
+		} else {
+			r.ReplaceInput(decrypted)
+		}
+	}
+	scte35.SpliceCommandType = common.SpliceCommandType(r.Uint8("splice_command_type"))
+	if r.Err() != nil {
+		return scte35.FinishDecode(input, r, problems, opts)
+	}
+
+	commandName := scte35.SpliceCommandType.String()
+	if commandName == "reserved" {
+		commandName = "splice_command"
+	}
+	sub := r.Sub(commandName, int(scte35.SpliceCommandLength)*8)
+	switch scte35.SpliceCommandType {
+	case 0x00:
+		scte35.SpliceNull = &common.SpliceNull{}
+	case 0x04:
+		scte35.SpliceSchedule = &common.SpliceSchedule{}
+		scte35.SpliceSchedule.DecodeFromBitReader(sub)
+	case 0x05:
+		scte35.SpliceInsert = &SpliceInsert{}
+		scte35.SpliceInsert.DecodeFromBitReader(sub)
+	case 0x06:
+		scte35.TimeSignal = &common.TimeSignal{}
+		scte35.TimeSignal.DecodeFromBitReader(sub)
+	case 0x07:
+		scte35.BandwidthReservation = &common.BandwidthReservation{}
+	case 0xff:
+		scte35.PrivateCommand = &common.PrivateCommand{}
+		scte35.PrivateCommand.DecodeFromBitReader(sub)
+	default:
+		sub.Fail("", "Unsupported Splice Command Type: "+strconv.Itoa(int(scte35.SpliceCommandType)))
+	}
+	if sub.Err() == nil && sub.Left() != 0 {
+		sub.Fail("", "The number of bits("+strconv.Itoa(sub.Pos())+") used by the splice command is not equal to the expected value: "+strconv.Itoa(int(scte35.SpliceCommandLength)*8))
+	}
+	if sub.Err() != nil && opts.Lenient {
+		problems = append(problems, sub.Err())
+		r.Skip(commandName, int(scte35.SpliceCommandLength)*8)
+	} else {
+		r.Merge(sub)
+	}
+
+	scte35.DescriptorLoopLength = r.Uint16("descriptor_loop_length")
+
+	loop := r.Sub("splice_descriptors", int(scte35.DescriptorLoopLength)*8)
+	for i := 0; loop.Err() == nil && loop.Left() > 0; i++ {
+		spliceDescriptor := &SpliceDescriptor{}
+		sub := loop.Sub("["+strconv.Itoa(i)+"]", -1)
+		spliceDescriptor.DecodeFromBitReader(sub)
+		if sub.Err() != nil && opts.Lenient {
+			//keep the descriptor as raw bytes and move on to the next one by descriptor_length
+			problems = append(problems, sub.Err())
+			spliceDescriptor = &SpliceDescriptor{}
+			sub = loop.Sub("["+strconv.Itoa(i)+"]", -1)
+			spliceDescriptor.DecodeAsUndecoded(sub, problems[len(problems)-1])
+		}
+		loop.Merge(sub)
+
+		scte35.SpliceDescriptors = append(scte35.SpliceDescriptors, *spliceDescriptor)
+	}
+	if loop.Err() != nil && opts.Lenient && r.Err() == nil {
+		problems = append(problems, loop.Err())
+		r.Skip("splice_descriptors", int(scte35.DescriptorLoopLength)*8)
+	} else {
+		r.Merge(loop)
+	}
+
+	bitRequiredForCRC32 := 32
+	if scte35.EncryptedPacket {
+		bitRequiredForCRC32 += 32
+	}
+	numOfBitsForStuffing := r.Left() - bitRequiredForCRC32
+	if r.Err() == nil && numOfBitsForStuffing%8 != 0 {
+		r.Fail("alignment_stuffing", "The number of bits left for alignment_stuffing is not divisible by 8")
+	}
+	if numOfBitsForStuffing > 0 {
+		alignmentStuffingInHex := r.HexString("alignment_stuffing", numOfBitsForStuffing/8)
+		scte35.AlignmentStuffingInHex = &alignmentStuffingInHex
+	}
+
+	if scte35.EncryptedPacket {
+		eCRC32InHex := r.HexString("e_crc_32", 4)
+		scte35.ECRC32InHex = &eCRC32InHex
+	}
+
+	scte35.CRC32InHex = r.HexString("crc_32", 4)
+
+	return scte35.FinishDecode(input, r, problems, opts)
+}
+
+//EncodeToRawBytes serializes the SCTE35 object to []byte, CRC_32(and E_CRC_32 of encrypted packet) is calculated
+//splice_command_length, descriptor_loop_length, section_length and the descriptor_length of every descriptor are recalculated
+func (scte35 *SCTE35) EncodeToRawBytes() (output []byte, err error) {
+	return scte35.EncodeToRawBytesWithOptions(common.EncodeOptions{})
+}
+
+//EncodeToRawBytesWithOptions is EncodeToRawBytes with the behaviour controlled by opts
+func (scte35 *SCTE35) EncodeToRawBytesWithOptions(opts common.EncodeOptions) (output []byte, err error) {
+	var commandBytes []byte
+	var descriptorBytes []byte
+	var tmpBytes []byte
+
+	switch scte35.SpliceCommandType {
+	case 0x00:
+		commandBytes = []byte{}
+	case 0x04:
+		if scte35.SpliceSchedule == nil {
+			return nil, common.MissingFieldError("splice_schedule")
+		}
+		commandBytes, err = scte35.SpliceSchedule.EncodeToRawBytes()
+	case 0x05:
+		if scte35.SpliceInsert == nil {
+			return nil, common.MissingFieldError("splice_insert")
+		}
+		commandBytes, err = scte35.SpliceInsert.EncodeToRawBytes()
+	case 0x06:
+		if scte35.TimeSignal == nil {
+			return nil, common.MissingFieldError("time_signal")
+		}
+		commandBytes, err = scte35.TimeSignal.EncodeToRawBytes()
+	case 0x07:
+		commandBytes = []byte{}
+	case 0xff:
+		if scte35.PrivateCommand == nil {
+			return nil, common.MissingFieldError("private_command")
+		}
+		commandBytes, err = scte35.PrivateCommand.EncodeToRawBytes()
+	default:
+		return nil, errors.New("Unsupported Splice Command Type: " + strconv.Itoa(int(scte35.SpliceCommandType)))
+	}
+	if err != nil {
+		return nil, errors.New("Unable To Encode Splice Command: " + err.Error())
+	}
+
+	for i := range scte35.SpliceDescriptors {
+		if tmpBytes, err = scte35.SpliceDescriptors[i].EncodeToRawBytes(); err != nil {
+			return nil, err
+		}
+		descriptorBytes = append(descriptorBytes, tmpBytes...)
+	}
+
+	stuffing := &common.BitWriter{}
+	if scte35.AlignmentStuffingInHex != nil {
+		if err = stuffing.WriteHexString(*scte35.AlignmentStuffingInHex); err != nil {
+			return nil, err
+		}
+	}
+	stuffingBytes, _ := stuffing.Bytes()
+	encrypt := scte35.EncryptedPacket && opts.Keys != nil
+	if encrypt {
+		stuffingBytes = common.EncryptionStuffing(stuffingBytes, 1+len(commandBytes)+2+len(descriptorBytes)+4) // splice_command_type, descriptor_loop_length and E_CRC_32 are encrypted as well
+		scte35.AlignmentStuffingInHex = nil
+		if len(stuffingBytes) > 0 {
+			alignmentStuffingInHex := hex.EncodeToString(stuffingBytes)
+			scte35.AlignmentStuffingInHex = &alignmentStuffingInHex
+		}
+	}
+
+	if len(commandBytes) > 0xfff {
+		return nil, errors.New("Encode Error: The splice command is longer than 4095 bytes")
+	}
+	scte35.SpliceCommandLength = uint16(len(commandBytes))
+	scte35.DescriptorLoopLength = uint16(len(descriptorBytes))
+
+	sectionLength := 11 + len(commandBytes) + 2 + len(descriptorBytes) + len(stuffingBytes) + 4 // 11 bytes from protocol_version to splice_command_type, 4 bytes for CRC_32
+	if scte35.EncryptedPacket {
+		sectionLength += 4 // E_CRC_32
+	}
+	if sectionLength > 0xfff {
+		return nil, errors.New("Encode Error: section_length(" + strconv.Itoa(sectionLength) + ") exceeds 4095 bytes")
+	}
+	scte35.SectionLength = uint16(sectionLength)
+
+	w := &common.BitWriter{}
+	w.WriteBits(uint64(scte35.TableID), 8)
+	w.WriteBool(scte35.SectionSyntaxIndicator)
+	w.WriteBool(scte35.PrivateIndicator)
+	w.WriteReserved(2)
+	w.WriteBits(uint64(scte35.SectionLength), 12)
+	w.WriteBits(uint64(scte35.ProtocolVersion), 8)
+	w.WriteBool(scte35.EncryptedPacket)
+	w.WriteBits(uint64(scte35.EncryptionAlgorithm), 6)
+	w.WriteBits(scte35.PTSAdjustment, 33)
+	w.WriteBits(uint64(scte35.CWIndex), 8)
+	w.WriteBits(uint64(scte35.Tier), 12)
+	w.WriteBits(uint64(scte35.SpliceCommandLength), 12)
+	w.WriteBits(uint64(scte35.SpliceCommandType), 8)
+	w.WriteBytes(commandBytes)
+	w.WriteBits(uint64(scte35.DescriptorLoopLength), 16)
+	w.WriteBytes(descriptorBytes)
+	w.WriteBytes(stuffingBytes)
+
+	//E_CRC_32 covers the portion to be encrypted, i.e. from splice_command_type to alignment_stuffing
+	if scte35.EncryptedPacket {
+		tmpBytes, _ = w.Bytes()
+		eCRC32InHex := common.CRC32MPEG2InHex(tmpBytes[13:])
+		scte35.ECRC32InHex = &eCRC32InHex
+		w.WriteHexString(eCRC32InHex)
+	}
+
+	tmpBytes, _ = w.Bytes()
+	if encrypt {
+		if tmpBytes, err = scte35.EncryptSection(tmpBytes, opts.Keys); err != nil {
+			return nil, err
+		}
+		w = &common.BitWriter{}
+		w.WriteBytes(tmpBytes)
+	}
+	scte35.CRC32InHex = common.CRC32MPEG2InHex(tmpBytes)
+	w.WriteHexString(scte35.CRC32InHex)
+
+	return w.Bytes()
+}
+
+func (scte35 *SCTE35) UnmarshalJSON(bytes []byte) (err error) {
+	type Alias SCTE35
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(scte35),
+	}
+	err = json.Unmarshal(bytes, &aux)
+
+	for _, spliceDesc := range scte35.SpliceDescriptors {
+		if spliceDesc.SegmentationDescriptor != nil {
+			spliceDesc.SegmentationDescriptor.DecodeSegmentationUpid()
+		}
+	}
+	return err
+}
+
+func (scte35 *SCTE35) DecodeFromJSON(jsonStr string) (err error) {
+	err = scte35.UnmarshalJSON([]byte(jsonStr))
+	return err
+}
+
+func (scte35 *SCTE35) JSON(indent ...string) (result string) {
+	var buf []byte
+	var err error
+
+	if len(indent) == 0 {
+		buf, err = json.Marshal(scte35)
+	} else {
+		buf, err = json.MarshalIndent(scte35, "", indent[0])
+	}
+
+	if err != nil {
+		panic(err)
+	}
+	return string(buf)
+}
+
+//XML serializes SCTE35 object to the SpliceInfoSection of the XML schema of SCTE35(http://www.scte.org/schemas/35)
+//Descriptors without an XML element(e.g. private or undecoded descriptors) and private bytes of descriptors are not written
+func (scte35 *SCTE35) XML(indent ...string) (result string) {
+	result, err := common.XMLString(scte35.EncodeToXML(), indent...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+//EncodeToXML converts SCTE35 object to its SpliceInfoSection element
+func (scte35 *SCTE35) EncodeToXML() *common.XMLSpliceInfoSection {
+	section := scte35.SCTE35.EncodeToXML()
+
+	switch {
+	case scte35.SpliceCommandType == 0x00:
+		section.SpliceNull = &common.XMLEmpty{}
+	case scte35.SpliceCommandType == 0x04 && scte35.SpliceSchedule != nil:
+		section.SpliceSchedule = scte35.SpliceSchedule.EncodeToXML()
+	case scte35.SpliceCommandType == 0x05 && scte35.SpliceInsert != nil:
+		section.SpliceInsert = scte35.SpliceInsert.EncodeToXML()
+	case scte35.SpliceCommandType == 0x06 && scte35.TimeSignal != nil:
+		section.TimeSignal = scte35.TimeSignal.EncodeToXML()
+	case scte35.SpliceCommandType == 0x07:
+		section.BandwidthReservation = &common.XMLEmpty{}
+	case scte35.SpliceCommandType == 0xff && scte35.PrivateCommand != nil:
+		section.PrivateCommand = scte35.PrivateCommand.EncodeToXML()
+	}
+
+	for i := range scte35.SpliceDescriptors {
+		if x := scte35.SpliceDescriptors[i].EncodeToXML(); x != nil {
+			section.SpliceDescriptors = append(section.SpliceDescriptors, *x)
+		}
+	}
+	return section
+}
+
+//DecodeFromXML parses the SpliceInfoSection of the XML schema of SCTE35 to SCTE35 object
+//The section is encoded and decoded again, so that the length fields, CRC_32 and the typed segmentation_upid are set as DecodeFromRawBytes does
+func (scte35 *SCTE35) DecodeFromXML(xmlStr string) (err error) {
+	section, err := common.ParseXMLSpliceInfoSection(xmlStr)
+	if err != nil {
+		return err
+	}
+
+	decoded := &SCTE35{}
+	if err = decoded.SCTE35.DecodeFromXML(section); err != nil {
+		return err
+	}
+	switch decoded.SpliceCommandType {
+	case 0x00:
+		decoded.SpliceNull = &common.SpliceNull{}
+	case 0x04:
+		decoded.SpliceSchedule = &common.SpliceSchedule{}
+		err = decoded.SpliceSchedule.DecodeFromXML(section.SpliceSchedule)
+	case 0x05:
+		decoded.SpliceInsert = &SpliceInsert{}
+		err = decoded.SpliceInsert.DecodeFromXML(section.SpliceInsert)
+	case 0x06:
+		decoded.TimeSignal = &common.TimeSignal{}
+		err = decoded.TimeSignal.DecodeFromXML(section.TimeSignal)
+	case 0x07:
+		decoded.BandwidthReservation = &common.BandwidthReservation{}
+	case 0xff:
+		decoded.PrivateCommand = &common.PrivateCommand{}
+		err = decoded.PrivateCommand.DecodeFromXML(section.PrivateCommand)
+	}
+	if err != nil {
+		return err
+	}
+
+	decoded.SpliceDescriptors = []SpliceDescriptor{}
+	for i := range section.SpliceDescriptors {
+		spliceDesc := SpliceDescriptor{}
+		if err = spliceDesc.DecodeFromXML(&section.SpliceDescriptors[i]); err != nil {
+			return errors.New(err.Error() + " (splice descriptor " + strconv.Itoa(i) + ")")
+		}
+		decoded.SpliceDescriptors = append(decoded.SpliceDescriptors, spliceDesc)
+	}
+
+	output, err := decoded.EncodeToRawBytes()
+	if err != nil {
+		return err
+	}
+	*scte35 = SCTE35{}
+	_, err = scte35.DecodeFromRawBytesWithOptions(output, common.DecodeOptions{Plaintext: true})
+	return err
+}
+
+//EncodeToXML converts SpliceDescriptor object to its XML element, nil is returned if the descriptor has no XML element
+func (spliceDesc *SpliceDescriptor) EncodeToXML() *common.XMLSpliceDescriptor {
+	if spliceDesc.UndecodedBytesInHex != nil {
+		return nil
+	}
+	switch {
+	case spliceDesc.SpliceDescriptorTag == 0x00 && spliceDesc.AvailDescriptor != nil:
+		return spliceDesc.AvailDescriptor.EncodeToXML()
+	case spliceDesc.SpliceDescriptorTag == 0x01 && spliceDesc.DTMFDescriptor != nil:
+		return spliceDesc.DTMFDescriptor.EncodeToXML()
+	case spliceDesc.SpliceDescriptorTag == 0x02 && spliceDesc.SegmentationDescriptor != nil:
+		return spliceDesc.SegmentationDescriptor.EncodeToXML()
+	case spliceDesc.SpliceDescriptorTag == 0x03 && spliceDesc.TimeDescriptor != nil:
+		return spliceDesc.TimeDescriptor.EncodeToXML()
+	case spliceDesc.SpliceDescriptorTag == 0x04 && spliceDesc.AudioDescriptor != nil:
+		return spliceDesc.AudioDescriptor.EncodeToXML()
+	}
+	return nil
+}
+
+//DecodeFromXML sets SpliceDescriptor object from its XML element, identifier is set to "CUEI"
+func (spliceDesc *SpliceDescriptor) DecodeFromXML(x *common.XMLSpliceDescriptor) (err error) {
+	*spliceDesc = SpliceDescriptor{}
+	spliceDesc.Identifier = common.CUEIIdentifier
+
+	switch x.XMLName.Local {
+	case "AvailDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x00
+		spliceDesc.AvailDescriptor = &common.AvailDescriptor{}
+		return spliceDesc.AvailDescriptor.DecodeFromXML(x)
+	case "DTMFDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x01
+		spliceDesc.DTMFDescriptor = &common.DTMFDescriptor{}
+		return spliceDesc.DTMFDescriptor.DecodeFromXML(x)
+	case "SegmentationDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x02
+		spliceDesc.SegmentationDescriptor = &SegmentationDescriptor{}
+		return spliceDesc.SegmentationDescriptor.DecodeFromXML(x)
+	case "TimeDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x03
+		spliceDesc.TimeDescriptor = &common.TimeDescriptor{}
+		return spliceDesc.TimeDescriptor.DecodeFromXML(x)
+	case "AudioDescriptor":
+		spliceDesc.SpliceDescriptorTag = 0x04
+		spliceDesc.AudioDescriptor = &AudioDescriptor{}
+		return spliceDesc.AudioDescriptor.DecodeFromXML(x)
+	}
+	return errors.New("Parse Error: unsupported element " + x.XMLName.Local + " in SpliceInfoSection")
+}
+
+//Validate reports the spec violations of the section as a list of findings
+//Reserved bits are only checked if the object is decoded by DecodeFromRawBytes
+func (scte35 *SCTE35) Validate() (findings []common.Finding) {
+	findings = append(findings, common.ValidateSection(&scte35.SCTE35)...)
+
+	if scte35.SpliceInsert != nil {
+		findings = append(findings, common.ValidateSpliceInsert(&scte35.SpliceInsert.SpliceInsert, "splice_insert")...)
+	}
+
+	for i := range scte35.SpliceDescriptors {
+		spliceDesc := &scte35.SpliceDescriptors[i]
+		path := "splice_descriptors[" + strconv.Itoa(i) + "]"
+
+		findings = append(findings, common.ValidateSpliceDescriptor(&spliceDesc.SpliceDescriptor, path)...)
+		if spliceDesc.DTMFDescriptor != nil {
+			findings = append(findings, common.ValidateDTMFDescriptor(spliceDesc.DTMFDescriptor, path+".dtmf_descriptor")...)
+		}
+		if spliceDesc.SegmentationDescriptor != nil {
+			findings = append(findings, common.ValidateSegmentationDescriptor(&spliceDesc.SegmentationDescriptor.SegmentationDescriptor, path+".segmentation_descriptor")...)
+			findings = append(findings, common.ValidateSubSegments(spliceDesc.SegmentationDescriptor.SubSegmentNum, spliceDesc.SegmentationDescriptor.SubSegmentsExpected, path+".segmentation_descriptor")...)
+		}
+	}
+
+	if raw := scte35.RawBytes(); raw != nil {
+		//encode a deep copy made through JSON, so that the length fields and CRC_32 of the object and its descriptors are kept
+		copied := &SCTE35{}
+		var encoded []byte
+		err := copied.DecodeFromJSON(scte35.JSON())
+		if err == nil {
+			encoded, err = copied.EncodeToRawBytes()
+		}
+		if err != nil {
+			findings = append(findings, common.Finding{Severity: common.SeverityError, Path: "", Message: "unable to re-encode the section: " + err.Error()})
+		} else {
+			findings = append(findings, common.ValidateReservedBits(raw, encoded, scte35.EncryptedPacket)...)
+		}
+	}
+
+	return findings
+}
+
+//SplicePTS returns the effective splice time of time_signal or program splice mode splice_insert, with pts_adjustment applied
+//ok is false if the command carries no splice time, e.g. splice_immediate_flag is set
+func (scte35 *SCTE35) SplicePTS() (pts uint64, ok bool) {
+	switch {
+	case scte35.TimeSignal != nil:
+		return scte35.TimeSignal.SpliceTime.EffectivePTS(scte35.PTSAdjustment)
+	case scte35.SpliceInsert != nil:
+		return scte35.SpliceInsert.SpliceTime.EffectivePTS(scte35.PTSAdjustment)
+	}
+	return 0, false
+}
+
+//JSONWithNames is JSON with the names of enum fields(e.g. "splice_command_type_name": "time_signal") added next to their numeric values
+func (scte35 *SCTE35) JSONWithNames(indent ...string) (result string) {
+	result, err := common.IndentedJSON(scte35, true, indent...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func (scte35 *SCTE35) SchemaVersion() string {
+	return "v2022"
+}
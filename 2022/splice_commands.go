@@ -0,0 +1,71 @@
+package schema_2022
+
+import (
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//SpliceInsert | splice_command_type = 0x05
+//event_id_compliance_flag is added to the reserved bits since SCTE35 2020
+type SpliceInsert struct {
+	common.SpliceInsert
+
+	EventIDComplianceFlag *bool `json:"event_id_compliance_flag,omitempty"`
+}
+
+//eventIDComplianceFlagBitPos is the position of event_id_compliance_flag, right after splice_immediate_flag
+const eventIDComplianceFlagBitPos = 32 + 1 + 7 + 4
+
+//DecodeFromRawBytes parses input []byte to SpliceInsert object
+func (spliceInsert *SpliceInsert) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := common.NewBitReader(input)
+	spliceInsert.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses SpliceInsert object from r
+func (spliceInsert *SpliceInsert) DecodeFromBitReader(r *common.BitReader) {
+	startPos := r.Pos()
+	spliceInsert.SpliceInsert.DecodeFromBitReader(r)
+
+	if r.Err() == nil && !spliceInsert.SpliceEventCancelIndicator {
+		eventIDComplianceFlag := r.Peek("event_id_compliance_flag", startPos+eventIDComplianceFlagBitPos, 1) == 1
+		spliceInsert.EventIDComplianceFlag = &eventIDComplianceFlag
+	}
+}
+
+//EncodeToRawBytes serializes SpliceInsert object to []byte
+func (spliceInsert *SpliceInsert) EncodeToRawBytes() (output []byte, err error) {
+	output, err = spliceInsert.SpliceInsert.EncodeToRawBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	//the flag occupies a reserved bit which is written as 1 by common.SpliceInsert
+	if !spliceInsert.SpliceEventCancelIndicator && spliceInsert.EventIDComplianceFlag != nil && !*spliceInsert.EventIDComplianceFlag {
+		output[eventIDComplianceFlagBitPos/8] &^= 0x80 >> uint(eventIDComplianceFlagBitPos%8)
+	}
+
+	return output, nil
+}
+
+//EncodeToXML converts SpliceInsert object to its XML element
+func (spliceInsert *SpliceInsert) EncodeToXML() *common.XMLSpliceInsert {
+	x := spliceInsert.SpliceInsert.EncodeToXML()
+	if !spliceInsert.SpliceEventCancelIndicator {
+		x.EventIDComplianceFlag = spliceInsert.EventIDComplianceFlag
+	}
+	return x
+}
+
+//DecodeFromXML sets SpliceInsert object from its XML element, event_id_compliance_flag is true if it is absent
+func (spliceInsert *SpliceInsert) DecodeFromXML(x *common.XMLSpliceInsert) (err error) {
+	*spliceInsert = SpliceInsert{}
+	if err = spliceInsert.SpliceInsert.DecodeFromXML(x); err != nil {
+		return err
+	}
+	if !spliceInsert.SpliceEventCancelIndicator {
+		eventIDComplianceFlag := x.EventIDComplianceFlag == nil || *x.EventIDComplianceFlag
+		spliceInsert.EventIDComplianceFlag = &eventIDComplianceFlag
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package schema_2022
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//SegmentationDescriptor | splice_descriptor_tag = 0x02
+//Since SCTE35 2019, sub_segment_num and sub_segments_expected are carried by more segmentation_type_id than 0x34 and 0x36,
+//and segmentation_event_id_compliance_indicator is added to the reserved bits since SCTE35 2020
+type SegmentationDescriptor struct {
+	common.SegmentationDescriptor
+
+	SegmentationEventIDComplianceIndicator *bool `json:"segmentation_event_id_compliance_indicator,omitempty"`
+
+	SubSegmentNum       *uint8 `json:"sub_segment_num,omitempty"`
+	SubSegmentsExpected *uint8 `json:"sub_segments_expected,omitempty"`
+}
+
+//segmentationEventIDComplianceIndicatorBitPos is the position of segmentation_event_id_compliance_indicator, right after segmentation_event_cancel_indicator
+const segmentationEventIDComplianceIndicatorBitPos = 32 + 1
+
+//HasSubSegments reports whether sub_segment_num and sub_segments_expected are defined for segmentationTypeID
+func HasSubSegments(segmentationTypeID common.SegmentationTypeID) bool {
+	switch segmentationTypeID {
+	case 0x30, 0x32, 0x34, 0x36, 0x38, 0x3A, 0x44, 0x46:
+		return true
+	}
+	return false
+}
+
+//DecodeFromRawBytes parses input []byte to SegmentationDescriptor object
+//input is expected to be limited to the descriptor, as many encoders omit the sub segment fields they are only decoded if there are bytes left
+func (segDesc *SegmentationDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := common.NewBitReader(input)
+	segDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses SegmentationDescriptor object from r, which is expected to be limited to the descriptor
+func (segDesc *SegmentationDescriptor) DecodeFromBitReader(r *common.BitReader) {
+	startPos := r.Pos()
+	segDesc.SegmentationDescriptor.DecodeFromBitReader(r)
+	if r.Err() != nil {
+		return
+	}
+
+	segmentationEventIDComplianceIndicator := r.Peek("segmentation_event_id_compliance_indicator", startPos+segmentationEventIDComplianceIndicatorBitPos, 1) == 1
+	segDesc.SegmentationEventIDComplianceIndicator = &segmentationEventIDComplianceIndicator
+
+	if !segDesc.SegmentationEventCancelIndicator && HasSubSegments(*segDesc.SegmentationTypeID) && r.Left() >= 16 {
+		subSegmentNum := r.Uint8("sub_segment_num")
+		segDesc.SubSegmentNum = &subSegmentNum
+
+		subSegmentsExpected := r.Uint8("sub_segments_expected")
+		segDesc.SubSegmentsExpected = &subSegmentsExpected
+	}
+}
+
+//EncodeToRawBytes serializes SegmentationDescriptor object to []byte
+func (segDesc *SegmentationDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	output, err = segDesc.SegmentationDescriptor.EncodeToRawBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	//the indicator occupies a reserved bit which is written as 1 by common.SegmentationDescriptor
+	if segDesc.SegmentationEventIDComplianceIndicator != nil && !*segDesc.SegmentationEventIDComplianceIndicator {
+		output[segmentationEventIDComplianceIndicatorBitPos/8] &^= 0x80 >> uint(segmentationEventIDComplianceIndicatorBitPos%8)
+	}
+
+	if !segDesc.SegmentationEventCancelIndicator && HasSubSegments(*segDesc.SegmentationTypeID) && segDesc.SubSegmentNum != nil {
+		if segDesc.SubSegmentsExpected == nil {
+			return nil, common.MissingFieldError("sub_segments_expected")
+		}
+		output = append(output, *segDesc.SubSegmentNum, *segDesc.SubSegmentsExpected)
+	}
+
+	return output, nil
+}
+
+//AudioDescriptor | splice_descriptor_tag = 0x04, added since SCTE35 2019
+type AudioDescriptor struct {
+	AudioCount    uint8          `json:"audio_count"` //4 bits
+	AudioChannels []AudioChannel `json:"audio_channels"`
+}
+
+type AudioChannel struct {
+	ComponentTag  byte   `json:"component_tag"`
+	ISOCode       string `json:"iso_code"`        //24 bits, ISO 639-2 language code
+	BitStreamMode uint8  `json:"bit_stream_mode"` //3 bits
+	NumChannels   uint8  `json:"num_channels"`    //4 bits
+	FullSrvcAudio bool   `json:"full_srvc_audio"`
+}
+
+//DecodeFromRawBytes parses input []byte to AudioDescriptor object
+func (audioDesc *AudioDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := common.NewBitReader(input)
+	audioDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses AudioDescriptor object from r
+func (audioDesc *AudioDescriptor) DecodeFromBitReader(r *common.BitReader) {
+	audioDesc.AudioCount = uint8(r.Bits("audio_count", 4))
+	r.Reserved(4)
+
+	audioDesc.AudioChannels = []AudioChannel{}
+	for i := 0; i < int(audioDesc.AudioCount) && r.Err() == nil; i++ {
+		channel := AudioChannel{}
+		sub := r.Sub("audio_channels["+strconv.Itoa(i)+"]", 40)
+
+		channel.ComponentTag = sub.Uint8("component_tag")
+		channel.ISOCode = sub.String("iso_code", 3)
+		channel.BitStreamMode = uint8(sub.Bits("bit_stream_mode", 3))
+		channel.NumChannels = uint8(sub.Bits("num_channels", 4))
+		channel.FullSrvcAudio = sub.Bool("full_srvc_audio")
+		r.Merge(sub)
+
+		audioDesc.AudioChannels = append(audioDesc.AudioChannels, channel)
+	}
+}
+
+//EncodeToRawBytes serializes AudioDescriptor object to []byte
+func (audioDesc *AudioDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	w := &common.BitWriter{}
+
+	if len(audioDesc.AudioChannels) > 0x0f {
+		return nil, errors.New("Encode Error: audio_count exceeds 15")
+	}
+	audioDesc.AudioCount = uint8(len(audioDesc.AudioChannels))
+
+	w.WriteBits(uint64(audioDesc.AudioCount), 4)
+	w.WriteReserved(4)
+	for _, channel := range audioDesc.AudioChannels {
+		if len(channel.ISOCode) != 3 {
+			return nil, errors.New("Encode Error: iso_code must be 3 characters: " + channel.ISOCode)
+		}
+		w.WriteBits(uint64(channel.ComponentTag), 8)
+		w.WriteBytes([]byte(channel.ISOCode))
+		w.WriteBits(uint64(channel.BitStreamMode), 3)
+		w.WriteBits(uint64(channel.NumChannels), 4)
+		w.WriteBool(channel.FullSrvcAudio)
+	}
+
+	return w.Bytes()
+}
+
+//EncodeToXML converts SegmentationDescriptor object to its XML element
+func (segDesc *SegmentationDescriptor) EncodeToXML() *common.XMLSpliceDescriptor {
+	x := segDesc.SegmentationDescriptor.EncodeToXML()
+	x.SegmentationEventIDComplianceIndicator = segDesc.SegmentationEventIDComplianceIndicator
+	x.SubSegmentNum = segDesc.SubSegmentNum
+	x.SubSegmentsExpected = segDesc.SubSegmentsExpected
+	return x
+}
+
+//DecodeFromXML sets SegmentationDescriptor object from its XML element, segmentation_event_id_compliance_indicator is true if it is absent
+func (segDesc *SegmentationDescriptor) DecodeFromXML(x *common.XMLSpliceDescriptor) (err error) {
+	*segDesc = SegmentationDescriptor{}
+	if err = segDesc.SegmentationDescriptor.DecodeFromXML(x); err != nil {
+		return err
+	}
+
+	segmentationEventIDComplianceIndicator := x.SegmentationEventIDComplianceIndicator == nil || *x.SegmentationEventIDComplianceIndicator
+	segDesc.SegmentationEventIDComplianceIndicator = &segmentationEventIDComplianceIndicator
+
+	if !segDesc.SegmentationEventCancelIndicator && HasSubSegments(*segDesc.SegmentationTypeID) && x.SubSegmentNum != nil {
+		subSegmentNum, subSegmentsExpected := *x.SubSegmentNum, uint8(0)
+		if x.SubSegmentsExpected != nil {
+			subSegmentsExpected = *x.SubSegmentsExpected
+		}
+		segDesc.SubSegmentNum = &subSegmentNum
+		segDesc.SubSegmentsExpected = &subSegmentsExpected
+	}
+	return nil
+}
+
+//EncodeToXML converts AudioDescriptor object to its XML element
+func (audioDesc *AudioDescriptor) EncodeToXML() *common.XMLSpliceDescriptor {
+	x := &common.XMLSpliceDescriptor{XMLName: xml.Name{Local: "AudioDescriptor"}, AudioChannels: []common.XMLAudioChannel{}}
+	for _, channel := range audioDesc.AudioChannels {
+		x.AudioChannels = append(x.AudioChannels, common.XMLAudioChannel{
+			ComponentTag:  channel.ComponentTag,
+			ISOCode:       channel.ISOCode,
+			BitStreamMode: channel.BitStreamMode,
+			NumChannels:   channel.NumChannels,
+			FullSrvcAudio: channel.FullSrvcAudio,
+		})
+	}
+	return x
+}
+
+//DecodeFromXML sets AudioDescriptor object from its XML element
+func (audioDesc *AudioDescriptor) DecodeFromXML(x *common.XMLSpliceDescriptor) (err error) {
+	audioDesc.AudioChannels = []AudioChannel{}
+	for _, channel := range x.AudioChannels {
+		audioDesc.AudioChannels = append(audioDesc.AudioChannels, AudioChannel{
+			ComponentTag:  channel.ComponentTag,
+			ISOCode:       channel.ISOCode,
+			BitStreamMode: channel.BitStreamMode & 0x07,
+			NumChannels:   channel.NumChannels & 0x0f,
+			FullSrvcAudio: channel.FullSrvcAudio,
+		})
+	}
+	audioDesc.AudioCount = uint8(len(audioDesc.AudioChannels))
+	return nil
+}
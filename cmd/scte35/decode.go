@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+)
+
+//runDecode decodes a splice_info_section and writes it to stdout
+//A CRC_32 mismatch does not stop the output, it is reported to stderr and by the exit code
+func runDecode(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "read input from `file`, - for stdin")
+	inFormat := flags.String("in", "auto", "input `format`: auto, hex, base64 or binary")
+	schema := flags.String("schema", "auto", "`schema` version: auto, 2013, 2017 or 2022")
+	outFormat := flags.String("o", "json", "output `format`: json, compact, yaml or text")
+	withNames := flags.Bool("names", false, "add the names of enum fields to json, compact and yaml output")
+	ignoreCRC := flags.Bool("ignore-crc", false, "exit with 0 even if CRC_32 does not match")
+	lenient := flags.Bool("lenient", false, "keep decoding after errors and write the partial result")
+	keys := keyFlag{}
+	flags.Var(keys, "key", "control word of an encrypted section as `cw_index=hex`, can be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: scte35 decode [flags] [input]")
+		fmt.Fprintln(stderr, "input is read from the argument, -f or stdin")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseFlags(flags, args)
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+	if err = checkInput(positional, *file); err == nil {
+		err = checkFormats(*inFormat, *schema, *outFormat)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitUsage
+	}
+
+	input, err := readInput(positional, *file, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+	section, err := decodeInput(input, *inFormat)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+
+	parser, err := decodeSection(section, *schema, common.DecodeOptions{IgnoreCRC32Mismatch: true, Lenient: *lenient, Keys: keys.provider()})
+	problems, isProblems := err.(common.DecodeErrors)
+	if err != nil && !isProblems {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+
+	output, err := format(parser, *outFormat, *withNames)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+	fmt.Fprint(stdout, output)
+
+	exitCode := exitOK
+	for _, problem := range problems {
+		if _, ok := problem.(*common.CRC32MismatchError); !ok {
+			fmt.Fprintln(stderr, "scte35: "+problem.Error())
+			exitCode = exitError
+		}
+	}
+	if mismatch := parser.CRC32Mismatch(); mismatch != nil {
+		fmt.Fprintln(stderr, "scte35: "+mismatch.Error())
+		if exitCode == exitOK && !*ignoreCRC {
+			exitCode = exitCRCMismatch
+		}
+	}
+	return exitCode
+}
+
+//checkFormats validates the flags before reading input
+func checkFormats(inFormat, schema, outFormat string) error {
+	if !inputFormats[inFormat] {
+		return errors.New("unknown input format " + inFormat + ", expected auto, hex, base64 or binary")
+	}
+	if schema != "auto" {
+		if _, err := decoder.NewParser(schema); err != nil {
+			return errors.New("unknown schema " + schema + ", expected auto, 2013, 2017 or 2022")
+		}
+	}
+	if !outputFormats[outFormat] {
+		return errors.New("unknown output format " + outFormat + ", expected json, compact, yaml or text")
+	}
+	return nil
+}
+
+//decodeSection decodes section by schema, auto chooses the schema by decoder.Detect
+//CRC_32 mismatch is not returned as an error, it is reported by CRC32Mismatch() of the parser
+func decodeSection(section []byte, schema string, opts common.DecodeOptions) (decoder.SchemaParser, error) {
+	opts.IgnoreCRC32Mismatch = true
+	if schema == "auto" {
+		parser, err := decoder.DecodeWithOptions(section, opts)
+		schemaParser, _ := parser.(decoder.SchemaParser)
+		return schemaParser, err
+	}
+
+	parser, err := decoder.NewParser(schema)
+	if err != nil {
+		return nil, err
+	}
+	_, err = parser.DecodeFromRawBytesWithOptions(section, opts)
+	return parser, err
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+	yaml "gopkg.in/yaml.v2"
+)
+
+//encodeDefaults are the values of the fields omitted from input, table_id of a section is always 0xFC and tier 0xFFF means no tiering
+const encodeDefaults = `{"table_id":252,"tier":4095}`
+
+var encodeInputFormats = map[string]bool{"auto": true, "json": true, "yaml": true}
+
+var encodeOutputFormats = map[string]bool{"hex": true, "base64": true, "binary": true}
+
+//runEncode reads a splice_info_section in the JSON of SCTE35.JSON()(or the same in YAML) and writes its raw bytes to stdout
+//The lengths and CRC_32 are recomputed, so they can be omitted from input, as well as table_id and tier, see encodeDefaults
+func runEncode(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("encode", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "read input from `file`, - for stdin")
+	inFormat := flags.String("in", "auto", "input `format`: auto, json or yaml")
+	schema := flags.String("schema", "2022", "`schema` version of input: 2013, 2017 or 2022")
+	outFormat := flags.String("o", "hex", "output `format`: hex, base64 or binary")
+	keys := keyFlag{}
+	flags.Var(keys, "key", "control word encrypting a section with encrypted_packet set as `cw_index=hex`, can be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: scte35 encode [flags] [input]")
+		fmt.Fprintln(stderr, "input is read from the argument, -f or stdin")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseFlags(flags, args)
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+	if err = checkInput(positional, *file); err == nil {
+		err = checkEncodeFormats(*inFormat, *schema, *outFormat)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitUsage
+	}
+
+	input, err := readInput(positional, *file, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+	jsonStr, err := toJSON(input, *inFormat)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+
+	parser, _ := decoder.NewParser(*schema)
+	if err = parser.DecodeFromJSON(encodeDefaults); err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+	if err = parser.DecodeFromJSON(jsonStr); err != nil {
+		fmt.Fprintln(stderr, "scte35: Parse Error: "+err.Error())
+		return exitError
+	}
+	section, err := parser.EncodeToRawBytesWithOptions(common.EncodeOptions{Keys: keys.provider()})
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+	if section[4]&0x80 != 0 && len(keys) == 0 {
+		fmt.Fprintln(stderr, "scte35: encrypted_packet is set but no -key is given, the encrypted portion is written in plaintext")
+	}
+
+	switch *outFormat {
+	case "hex":
+		fmt.Fprintln(stdout, hex.EncodeToString(section))
+	case "base64":
+		fmt.Fprintln(stdout, base64.StdEncoding.EncodeToString(section))
+	case "binary":
+		if _, err = stdout.Write(section); err != nil {
+			fmt.Fprintln(stderr, "scte35: "+err.Error())
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+//checkEncodeFormats validates the flags of encode before reading input
+func checkEncodeFormats(inFormat, schema, outFormat string) error {
+	if !encodeInputFormats[inFormat] {
+		return errors.New("unknown input format " + inFormat + ", expected auto, json or yaml")
+	}
+	if _, err := decoder.NewParser(schema); err != nil {
+		return errors.New("unknown schema " + schema + ", expected 2013, 2017 or 2022")
+	}
+	if !encodeOutputFormats[outFormat] {
+		return errors.New("unknown output format " + outFormat + ", expected hex, base64 or binary")
+	}
+	return nil
+}
+
+//toJSON returns input in JSON, auto takes input starting with "{" as JSON and the others as YAML
+func toJSON(input []byte, inFormat string) (string, error) {
+	text := strings.TrimSpace(string(input))
+	if text == "" {
+		return "", errors.New("Parse Error: input is empty")
+	}
+	if inFormat == "json" || (inFormat == "auto" && strings.HasPrefix(text, "{")) {
+		return text, nil
+	}
+
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(text), &value); err != nil {
+		return "", errors.New("Parse Error: invalid YAML input: " + err.Error())
+	}
+	value, err := fromYAML(value)
+	if err != nil {
+		return "", err
+	}
+	output, err := json.Marshal(value)
+	return string(output), err
+}
+
+//fromYAML converts the maps unmarshalled by yaml to the ones accepted by json.Marshal
+func fromYAML(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, element := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, errors.New("Parse Error: YAML key must be a string: " + fmt.Sprint(key))
+			}
+			converted, err := fromYAML(element)
+			if err != nil {
+				return nil, errors.New("Parse Error: " + name + ": " + strings.TrimPrefix(err.Error(), "Parse Error: "))
+			}
+			object[name] = converted
+		}
+		return object, nil
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i := range v {
+			converted, err := fromYAML(v[i])
+			if err != nil {
+				return nil, errors.New("Parse Error: [" + strconv.Itoa(i) + "]: " + strings.TrimPrefix(err.Error(), "Parse Error: "))
+			}
+			array[i] = converted
+		}
+		return array, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+)
+
+//bytesPerRow is the number of bytes in a row of the hexdump
+const bytesPerRow = 8
+
+//maxBitsShown is the longest field whose bits are written by the hexdump, the bytes column shows the longer ones
+const maxBitsShown = 40
+
+const (
+	colorRed   = "\x1b[31m"
+	colorReset = "\x1b[0m"
+)
+
+var explainOutputFormats = map[string]bool{"text": true, "json": true}
+
+//explanation is the result of explain, it is written as JSON by -o json
+type explanation struct {
+	SchemaVersion string           `json:"schema_version"`
+	Fields        []explainedField `json:"fields"`
+	Leftover      []leftoverBytes  `json:"leftover,omitempty"`
+	Errors        []string         `json:"errors,omitempty"`
+
+	section []byte
+}
+
+type explainedField struct {
+	common.Field
+	Bits string `json:"bits"`
+}
+
+//leftoverBytes are bytes of input which are not read as any field
+type leftoverBytes struct {
+	ByteOffset int    `json:"byte_offset"`
+	BytesInHex string `json:"bytes_in_hex"`
+	Reason     string `json:"reason"`
+}
+
+//runExplain writes every field of a splice_info_section with its bit offset, raw bits and value as an annotated hexdump
+//Input which fails to decode is explained up to the failure, followed by the bytes left
+func runExplain(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "read input from `file`, - for stdin")
+	inFormat := flags.String("in", "auto", "input `format`: auto, hex, base64 or binary")
+	schema := flags.String("schema", "auto", "`schema` version: auto, 2013, 2017 or 2022")
+	outFormat := flags.String("o", "text", "output `format`: text or json")
+	color := flags.Bool("color", false, "highlight violations and leftover bytes in red")
+	ignoreCRC := flags.Bool("ignore-crc", false, "exit with 0 even if CRC_32 does not match")
+	keys := keyFlag{}
+	flags.Var(keys, "key", "control word of an encrypted section as `cw_index=hex`, can be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: scte35 explain [flags] [input]")
+		fmt.Fprintln(stderr, "input is read from the argument, -f or stdin")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseFlags(flags, args)
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+	if err = checkInput(positional, *file); err == nil {
+		err = checkFormats(*inFormat, *schema, "json")
+	}
+	if err == nil && !explainOutputFormats[*outFormat] {
+		err = errors.New("unknown output format " + *outFormat + ", expected text or json")
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitUsage
+	}
+
+	input, err := readInput(positional, *file, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+	section, err := decodeInput(input, *inFormat)
+	if err != nil {
+		fmt.Fprintln(stderr, "scte35: "+err.Error())
+		return exitError
+	}
+
+	parser, decodeErr := explainSection(section, *schema, keys.provider())
+	result := explain(section, parser, decodeErr)
+
+	exitCode := exitOK
+	if decodeErr != nil {
+		exitCode = exitError
+	} else if parser.CRC32Mismatch() != nil && !*ignoreCRC {
+		exitCode = exitCRCMismatch
+	}
+
+	if *outFormat == "json" {
+		output, _ := json.MarshalIndent(result, "", "\t")
+		fmt.Fprintln(stdout, string(output))
+		return exitCode
+	}
+	fmt.Fprint(stdout, hexdump(result, *color))
+	return exitCode
+}
+
+//explainSection decodes section with the fields recorded, auto chooses the schema by decoder.Detect
+//If no schema is able to decode section, the one which reads the most bits before failing is returned
+func explainSection(section []byte, schema string, keys common.KeyProvider) (decoder.SchemaParser, error) {
+	opts := common.DecodeOptions{IgnoreCRC32Mismatch: true, RecordFields: true, Keys: keys}
+	if schema != "auto" {
+		return decodeSection(section, schema, opts)
+	}
+
+	parser, err := decodeSection(section, schema, opts)
+	if err == nil {
+		return parser, nil
+	}
+	var best decoder.SchemaParser
+	var bestErr error
+	for _, version := range []string{"2022", "2017", "2013"} {
+		parser, err := decodeSection(section, version, opts)
+		if best == nil || bitsRead(parser) > bitsRead(best) {
+			best, bestErr = parser, err
+		}
+	}
+	return best, bestErr
+}
+
+func bitsRead(parser decoder.SchemaParser) int {
+	fields := parser.Fields()
+	if len(fields) == 0 {
+		return 0
+	}
+	last := fields[len(fields)-1]
+	return last.BitOffset + last.BitLength
+}
+
+//explain collects the fields of parser and the bytes of section which are not read as any field
+//The bytes of an encrypted section are written decrypted, so that they match the fields
+func explain(section []byte, parser decoder.SchemaParser, decodeErr error) *explanation {
+	if decrypted, ok := parser.(interface{ RawBytes() []byte }); ok && len(decrypted.RawBytes()) == len(section) {
+		section = decrypted.RawBytes()
+	}
+	result := &explanation{SchemaVersion: parser.SchemaVersion(), section: section}
+	mismatch := parser.CRC32Mismatch()
+
+	next := 0 //the first bit not read yet
+	for _, field := range parser.Fields() {
+		if field.BitOffset/8 > (next+7)/8 {
+			result.Leftover = append(result.Leftover, leftoverBytes{ByteOffset: (next + 7) / 8, BytesInHex: hex.EncodeToString(section[(next+7)/8 : field.BitOffset/8]), Reason: "not read as any field"})
+		}
+		if mismatch != nil && field.Path == mismatch.FieldName {
+			field.Violation = mismatch.Error()
+		}
+		result.Fields = append(result.Fields, explainedField{Field: field, Bits: field.BitString()})
+		if end := field.BitOffset + field.BitLength; end > next {
+			next = end
+		}
+	}
+
+	if first := (next + 7) / 8; first < len(section) {
+		reason := "not read as any field"
+		if decodeErr != nil {
+			reason = "left after the parse error"
+		}
+		result.Leftover = append(result.Leftover, leftoverBytes{ByteOffset: first, BytesInHex: hex.EncodeToString(section[first:]), Reason: reason})
+	}
+
+	if decodeErr != nil {
+		result.Errors = append(result.Errors, decodeErr.Error())
+	}
+	if len(section) >= 3 {
+		sectionLength := int(section[1]&0x0F)<<8 | int(section[2])
+		if sectionLength+3 != len(section) {
+			result.Errors = append(result.Errors, "input has "+strconv.Itoa(len(section))+" bytes, while section_length tells "+strconv.Itoa(sectionLength+3))
+		}
+	}
+	return result
+}
+
+//hexdump renders result as rows of "byte offset, bytes, bits, field = value", the bytes of a field are written by the first field using them
+func hexdump(result *explanation, color bool) string {
+	var output bytes.Buffer
+	highlight := func(line string) string {
+		if color {
+			return colorRed + line + colorReset
+		}
+		return line
+	}
+
+	output.WriteString("schema_version: " + result.SchemaVersion + "\n")
+	output.WriteString(fmt.Sprintf("%-6s %-*s %-*s %s\n", "offset", bytesPerRow*3-1, "bytes", maxBitsShown, "bits", "bit_offset+bit_length field = value"))
+
+	violations := 0
+	nextByte := 0 //the first byte not written yet
+	leftover := result.Leftover
+	for _, field := range result.Fields {
+		for len(leftover) > 0 && leftover[0].ByteOffset < field.BitOffset/8 {
+			output.WriteString(highlight(dumpLeftover(leftover[0])))
+			leftover = leftover[1:]
+		}
+
+		label := strconv.Itoa(field.BitOffset) + "+" + strconv.Itoa(field.BitLength) + " " + field.Path
+		if field.Value != "" {
+			label += " = " + field.Value
+		}
+		if field.Violation != "" {
+			label += "  !! " + field.Violation
+			violations++
+		}
+
+		fieldBits := field.Bits
+		if len(fieldBits) > maxBitsShown {
+			fieldBits = ""
+		}
+
+		first := field.BitOffset / 8
+		if first < nextByte {
+			first = nextByte
+		}
+		end := (field.BitOffset + field.BitLength + 7) / 8
+		rows := byteRows(result.section, first, end)
+		offset := ""
+		if len(rows) > 0 {
+			offset = fmt.Sprintf("%04x", first)
+		}
+		line := fmt.Sprintf("%-6s %-*s %-*s %s", offset, bytesPerRow*3-1, firstOrEmpty(rows), maxBitsShown, fieldBits, label)
+		if field.Violation != "" {
+			line = highlight(line)
+		}
+		output.WriteString(line + "\n")
+		for i := 1; i < len(rows); i++ {
+			output.WriteString(fmt.Sprintf("%04x   %s\n", first+i*bytesPerRow, rows[i]))
+		}
+
+		if end > nextByte {
+			nextByte = end
+		}
+	}
+	for _, l := range leftover {
+		output.WriteString(highlight(dumpLeftover(l)))
+	}
+
+	if violations > 0 {
+		output.WriteString(highlight(strconv.Itoa(violations)+" violation(s) found") + "\n")
+	}
+	for _, errMsg := range result.Errors {
+		output.WriteString(highlight("!! "+errMsg) + "\n")
+	}
+	return output.String()
+}
+
+//byteRows returns the bytes [first, end) of section, bytesPerRow bytes in a row
+func byteRows(section []byte, first int, end int) (rows []string) {
+	for i := first; i < end; i += bytesPerRow {
+		rowEnd := i + bytesPerRow
+		if rowEnd > end {
+			rowEnd = end
+		}
+		var row []string
+		for _, b := range section[i:rowEnd] {
+			row = append(row, hex.EncodeToString([]byte{b}))
+		}
+		rows = append(rows, strings.Join(row, " "))
+	}
+	return rows
+}
+
+func firstOrEmpty(rows []string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	return rows[0]
+}
+
+func dumpLeftover(l leftoverBytes) string {
+	raw, _ := hex.DecodeString(l.BytesInHex)
+	var output bytes.Buffer
+	for i, row := range byteRows(raw, 0, len(raw)) {
+		label := ""
+		if i == 0 {
+			label = "!! " + strconv.Itoa(len(raw)) + " byte(s) " + l.Reason
+		}
+		output.WriteString(fmt.Sprintf("%04x   %-*s %-*s %s\n", l.ByteOffset+i*bytesPerRow, bytesPerRow*3-1, row, maxBitsShown, "", label))
+	}
+	return output.String()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+var inputFormats = map[string]bool{"auto": true, "hex": true, "base64": true, "binary": true}
+
+//checkInput reports whether input is given by at most one of the positional argument and file
+func checkInput(positional []string, file string) error {
+	if len(positional) > 1 {
+		return errors.New("too many arguments: " + strings.Join(positional, " "))
+	}
+	if len(positional) == 1 && file != "" {
+		return errors.New("input is given by both argument and -f")
+	}
+	return nil
+}
+
+//readInput returns the positional argument, the content of file("-" for stdin), or stdin if neither is given
+func readInput(positional []string, file string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case len(positional) > 0:
+		return []byte(positional[0]), nil
+	case file != "" && file != "-":
+		return os.ReadFile(file)
+	}
+	return io.ReadAll(stdin)
+}
+
+//decodeInput converts input in format(auto, hex, base64 or binary) to a splice_info_section
+//auto takes input starting with table_id 0xFC as binary, input with 0x prefix or only hex digits as hex, and the others as base64
+func decodeInput(input []byte, format string) ([]byte, error) {
+	if format == "binary" || (format == "auto" && len(input) > 0 && input[0] == 0xFC) {
+		return input, nil
+	}
+
+	text := strings.Join(strings.Fields(string(input)), "")
+	if text == "" {
+		return nil, errors.New("Parse Error: input is empty")
+	}
+	switch format {
+	case "hex":
+		return decodeHex(text)
+	case "base64":
+		return decodeBase64(text)
+	case "auto":
+		if section, err := decodeHex(text); err == nil {
+			return section, nil
+		}
+		return decodeBase64(text)
+	}
+	return nil, errors.New("unknown input format " + format + ", expected auto, hex, base64 or binary")
+}
+
+func decodeHex(text string) ([]byte, error) {
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		text = text[2:]
+	}
+	section, err := hex.DecodeString(text)
+	if err != nil {
+		return nil, errors.New("Parse Error: invalid hex input: " + err.Error())
+	}
+	return section, nil
+}
+
+func decodeBase64(text string) ([]byte, error) {
+	section, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		//base64 without padding, or base64url
+		if section, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(text, "=")); err != nil {
+			section, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(text, "="))
+		}
+	}
+	if err != nil {
+		return nil, errors.New("Parse Error: invalid base64 input: " + err.Error())
+	}
+	return section, nil
+}
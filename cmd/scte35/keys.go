@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//keyFlag collects the control words given by repeated -key cw_index=hex flags
+type keyFlag common.KeyTable
+
+func (keys keyFlag) String() string {
+	var indexes []string
+	for cwIndex := range keys {
+		indexes = append(indexes, strconv.Itoa(int(cwIndex)))
+	}
+	sort.Strings(indexes)
+	return strings.Join(indexes, ",") //the control words are not written
+}
+
+func (keys keyFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return errors.New("expected cw_index=hex, e.g. 0=0123456789abcdef")
+	}
+	cwIndex, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 8)
+	if err != nil {
+		return errors.New("invalid cw_index " + parts[0])
+	}
+	key, err := decodeHex(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return errors.New("invalid control word of cw_index " + parts[0])
+	}
+	keys[uint8(cwIndex)] = key
+	return nil
+}
+
+//provider returns the control words as common.KeyProvider, nil if no -key is given
+func (keys keyFlag) provider() common.KeyProvider {
+	if len(keys) == 0 {
+		return nil
+	}
+	return common.KeyTable(keys)
+}
@@ -0,0 +1,74 @@
+//Command scte35 decodes and encodes splice_info_sections for scripting and debugging
+//
+//	scte35 decode [flags] [input]
+//	scte35 encode [flags] [input]
+//	scte35 explain [flags] [input]
+//
+//The exit code is 0 on success, 1 on parse or IO errors, 2 on usage errors and 3 on CRC_32 mismatches
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+//exit codes of the commands
+const (
+	exitOK          = 0
+	exitError       = 1 //parse error or IO error
+	exitUsage       = 2
+	exitCRCMismatch = 3
+)
+
+const usage = `Usage: scte35 <command> [flags] [input]
+
+Commands:
+  decode    decode a splice_info_section in hex, base64 or binary
+  encode    encode a splice_info_section in JSON or YAML to hex, base64 or binary
+  explain   write every field of a splice_info_section with its bit offset as an annotated hexdump
+
+Run "scte35 <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+//run executes the command of args and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "decode":
+		return runDecode(args[1:], stdin, stdout, stderr)
+	case "encode":
+		return runEncode(args[1:], stdin, stdout, stderr)
+	case "explain":
+		return runExplain(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	fmt.Fprintln(stderr, "scte35: unknown command "+args[0])
+	fmt.Fprint(stderr, usage)
+	return exitUsage
+}
+
+//parseFlags parses args by flags and returns the positional arguments, flags are also accepted after them
+func parseFlags(flags *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		if err = flags.Parse(args); err != nil {
+			return positional, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var outputFormats = map[string]bool{"json": true, "compact": true, "yaml": true, "text": true}
+
+//member is a member of a JSON object
+type member struct {
+	key   string
+	value interface{}
+}
+
+//orderedObject is a JSON object which keeps the order of its members
+type orderedObject []member
+
+//format renders parser in format(json, compact, yaml or text), names are added next to the enum fields if withNames is true
+//The text format always has the names
+func format(parser decoder.SchemaParser, outFormat string, withNames bool) (string, error) {
+	toJSON := parser.JSON
+	if withNames {
+		toJSON = parser.JSONWithNames
+	}
+
+	switch outFormat {
+	case "json":
+		return toJSON("\t") + "\n", nil
+	case "compact":
+		return toJSON() + "\n", nil
+	case "yaml":
+		value, err := parseOrdered([]byte(toJSON()))
+		if err != nil {
+			return "", err
+		}
+		output, err := yaml.Marshal(toYAML(value))
+		return string(output), err
+	case "text":
+		value, err := parseOrdered([]byte(parser.JSONWithNames()))
+		if err != nil {
+			return "", err
+		}
+		var output bytes.Buffer
+		output.WriteString("schema_version: " + parser.SchemaVersion() + "\n")
+		writeText(&output, value, "")
+		return output.String(), nil
+	}
+	return "", errors.New("unknown output format " + outFormat + ", expected json, compact, yaml or text")
+}
+
+//parseOrdered parses JSON into orderedObject, []interface{}, json.Number, string, bool or nil
+func parseOrdered(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return readValue(d)
+}
+
+func readValue(d *json.Decoder) (interface{}, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := orderedObject{}
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readValue(d)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, member{key: key.(string), value: value})
+		}
+		_, err = d.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for d.More() {
+			value, err := readValue(d)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = d.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+//toYAML converts the result of parseOrdered to the values marshalled by yaml in the same order
+func toYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case orderedObject:
+		mapSlice := yaml.MapSlice{}
+		for _, m := range v {
+			mapSlice = append(mapSlice, yaml.MapItem{Key: m.key, Value: toYAML(m.value)})
+		}
+		return mapSlice
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i := range v {
+			array[i] = toYAML(v[i])
+		}
+		return array
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+//writeText writes value as indented "key: value" lines, the name of an enum field is put after its value, e.g. "splice_command_type: 6 (time_signal)"
+func writeText(output *bytes.Buffer, value interface{}, indent string) {
+	switch v := value.(type) {
+	case orderedObject:
+		for i := 0; i < len(v); i++ {
+			m := v[i]
+			if isComposite(m.value) {
+				output.WriteString(indent + m.key + ":\n")
+				writeText(output, m.value, indent+"  ")
+				continue
+			}
+
+			line := indent + m.key + ": " + textOf(m.value)
+			if number, ok := m.value.(json.Number); ok && common.TicksFieldNames[m.key] {
+				if ticks, err := strconv.ParseUint(string(number), 10, 64); err == nil {
+					line += " (" + strconv.FormatFloat(float64(ticks)/90000, 'f', 3, 64) + "s)"
+				}
+			}
+			if i+1 < len(v) && v[i+1].key == m.key+"_name" {
+				if name, ok := v[i+1].value.(string); ok {
+					line += " (" + name + ")"
+					i++
+				}
+			}
+			output.WriteString(line + "\n")
+		}
+	case []interface{}:
+		for i, element := range v {
+			if isComposite(element) {
+				output.WriteString(indent + "[" + strconv.Itoa(i) + "]:\n")
+				writeText(output, element, indent+"  ")
+			} else {
+				output.WriteString(indent + "[" + strconv.Itoa(i) + "]: " + textOf(element) + "\n")
+			}
+		}
+	default:
+		output.WriteString(indent + textOf(value) + "\n")
+	}
+}
+
+//isComposite reports whether value is a non-empty object or array, which is written on its own lines
+func isComposite(value interface{}) bool {
+	switch v := value.(type) {
+	case orderedObject:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+func textOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" || strings.TrimSpace(v) != v {
+			return strconv.Quote(v)
+		}
+		return v
+	case json.Number:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case orderedObject:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return ""
+}
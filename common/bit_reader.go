@@ -0,0 +1,290 @@
+package common
+
+import (
+	"encoding/hex"
+	"strconv"
+)
+
+//ParseError reports where and why decoding failed
+type ParseError struct {
+	Path          string //structure path of the field, e.g. splice_descriptors[1].segmentation_descriptor.segmentation_upid
+	BitOffset     int    //bit offset of the field from the start of the input
+	ExpectedBits  int    //number of bits required by the field, 0 if the error is not caused by truncated input
+	AvailableBits int    //number of bits left in the enclosing structure
+	Message       string
+}
+
+func (e *ParseError) Error() string {
+	errMsg := "Parse Error: " + e.Path + " at bit offset " + strconv.Itoa(e.BitOffset)
+	if e.ExpectedBits > 0 {
+		errMsg += ": expected " + strconv.Itoa(e.ExpectedBits) + " bits, " + strconv.Itoa(e.AvailableBits) + " bits available"
+	}
+	if e.Message != "" {
+		errMsg += ": " + e.Message
+	}
+	return errMsg
+}
+
+//BitReader reads fields MSB first, it is the counterpart of BitWriter used by DecodeFromRawBytes
+//The first failure is kept as *ParseError and all later reads return zero values, so callers only need to check Err() once
+type BitReader struct {
+	input []byte
+	limit int //number of readable bits in input
+	pos   int
+	base  int //bit offset of input[0] from the start of the outermost input
+	path  string
+	err   error
+
+	recorder *fieldRecorder //nil unless Record is called
+}
+
+//NewBitReader returns a BitReader reading input from its first bit
+func NewBitReader(input []byte) *BitReader {
+	return &BitReader{input: input, limit: len(input) * 8}
+}
+
+//Err returns the first error met by the reader
+func (r *BitReader) Err() error {
+	return r.err
+}
+
+//Result returns the number of bits read and the first error, in the form returned by DecodeFromRawBytes
+func (r *BitReader) Result() (numOfParsedBits int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.pos, nil
+}
+
+//Pos returns the number of bits read so far
+func (r *BitReader) Pos() int {
+	return r.pos
+}
+
+//Left returns the number of bits left
+func (r *BitReader) Left() int {
+	return r.limit - r.pos
+}
+
+//BitOffset returns the bit offset of the next field from the start of the outermost input
+func (r *BitReader) BitOffset() int {
+	return r.base + r.pos
+}
+
+//Path returns the structure path of the reader
+func (r *BitReader) Path() string {
+	return r.path
+}
+
+//FieldPath returns the structure path of the field named name
+func (r *BitReader) FieldPath(name string) string {
+	if r.path == "" {
+		return name
+	}
+	if name == "" {
+		return r.path
+	}
+	if name[0] == '[' {
+		return r.path + name
+	}
+	return r.path + "." + name
+}
+
+//Fail records a *ParseError of the field named name at the current position, unless there is an error already
+func (r *BitReader) Fail(name string, message string) {
+	if r.err == nil {
+		r.err = &ParseError{Path: r.FieldPath(name), BitOffset: r.BitOffset(), AvailableBits: r.Left(), Message: message}
+	}
+}
+
+//SetErr records err as the error of the reader unless there is an error already
+func (r *BitReader) SetErr(err error) {
+	if r.err == nil && err != nil {
+		r.err = err
+	}
+}
+
+func (r *BitReader) require(name string, numOfBits int) bool {
+	if r.err != nil {
+		return false
+	}
+	if numOfBits > r.Left() {
+		r.err = &ParseError{Path: r.FieldPath(name), BitOffset: r.BitOffset(), ExpectedBits: numOfBits, AvailableBits: r.Left()}
+		return false
+	}
+	return true
+}
+
+//Bits reads numOfBits(at most 64) bits as an unsigned integer
+func (r *BitReader) Bits(name string, numOfBits int) uint64 {
+	bitOffset := r.BitOffset()
+	value, raw, ok := r.read(name, numOfBits)
+	if ok {
+		r.recordUint(name, bitOffset, numOfBits, raw, value)
+	}
+	return value
+}
+
+func (r *BitReader) read(name string, numOfBits int) (value uint64, raw []byte, ok bool) {
+	if !r.require(name, numOfBits) {
+		return 0, nil, false
+	}
+
+	tmpBytes := subBits(r.input, r.pos, numOfBits)
+	for _, b := range tmpBytes {
+		value = value<<8 | uint64(b)
+	}
+	value >>= uint((8 - numOfBits%8) % 8)
+
+	r.pos += numOfBits
+	return value, tmpBytes, true
+}
+
+//Bool reads a single bit flag
+func (r *BitReader) Bool(name string) bool {
+	bitOffset := r.BitOffset()
+	value, raw, ok := r.read(name, 1)
+	if ok {
+		r.record(name, bitOffset, 1, raw, strconv.FormatBool(value == 1))
+	}
+	return value == 1
+}
+
+//Uint8 reads 8 bits
+func (r *BitReader) Uint8(name string) uint8 {
+	return uint8(r.Bits(name, 8))
+}
+
+//Uint16 reads 16 bits
+func (r *BitReader) Uint16(name string) uint16 {
+	return uint16(r.Bits(name, 16))
+}
+
+//Uint32 reads 32 bits
+func (r *BitReader) Uint32(name string) uint32 {
+	return uint32(r.Bits(name, 32))
+}
+
+//Peek reads numOfBits bits at bitPos(counted from the start of the reader) without moving the reader
+//It is used for flags which were carved out of reserved bits by later revisions of the standard
+func (r *BitReader) Peek(name string, bitPos int, numOfBits int) uint64 {
+	pos := r.pos
+	r.pos = bitPos
+	bitOffset := r.BitOffset()
+	value, raw, ok := r.read(name, numOfBits)
+	r.pos = pos
+	if ok && r.recorder != nil {
+		text := strconv.FormatUint(value, 10)
+		if numOfBits == 1 {
+			text = strconv.FormatBool(value == 1)
+		}
+		r.recorder.carve(Field{Path: r.FieldPath(name), BitOffset: bitOffset, BitLength: numOfBits, Raw: raw, Value: text})
+	}
+	return value
+}
+
+//Reserved skips numOfBits reserved bits
+func (r *BitReader) Reserved(numOfBits int) {
+	bitOffset := r.BitOffset()
+	if _, raw, ok := r.read("reserved", numOfBits); ok {
+		r.recordReserved(bitOffset, numOfBits, raw)
+	}
+}
+
+//Bytes reads numOfBytes bytes
+func (r *BitReader) Bytes(name string, numOfBytes int) []byte {
+	bitOffset := r.BitOffset()
+	tmpBytes, ok := r.bytes(name, numOfBytes)
+	if ok {
+		r.record(name, bitOffset, numOfBytes*8, tmpBytes, hex.EncodeToString(tmpBytes))
+	}
+	return tmpBytes
+}
+
+func (r *BitReader) bytes(name string, numOfBytes int) ([]byte, bool) {
+	if !r.require(name, numOfBytes*8) {
+		return nil, false
+	}
+
+	tmpBytes := subBits(r.input, r.pos, numOfBytes*8)
+	r.pos += numOfBytes * 8
+	return tmpBytes, true
+}
+
+//HexString reads numOfBytes bytes as hex string
+func (r *BitReader) HexString(name string, numOfBytes int) string {
+	return hex.EncodeToString(r.Bytes(name, numOfBytes))
+}
+
+//String reads numOfBytes bytes as string
+func (r *BitReader) String(name string, numOfBytes int) string {
+	bitOffset := r.BitOffset()
+	tmpBytes, ok := r.bytes(name, numOfBytes)
+	if ok {
+		r.record(name, bitOffset, numOfBytes*8, tmpBytes, strconv.Quote(string(tmpBytes)))
+	}
+	return string(tmpBytes)
+}
+
+//Sub returns a reader of the structure named name, which starts at the current position and is limited to numOfBits bits
+//A negative numOfBits means the rest of the input. The parent is advanced by Merge(sub) after decoding the structure
+//If numOfBits exceeds the bits left, the sub reader fails immediately and the error reaches r by Merge
+func (r *BitReader) Sub(name string, numOfBits int) *BitReader {
+	err := r.err
+	if numOfBits < 0 || numOfBits > r.Left() {
+		if numOfBits >= 0 && err == nil {
+			err = &ParseError{Path: r.FieldPath(name), BitOffset: r.BitOffset(), ExpectedBits: numOfBits, AvailableBits: r.Left()}
+		}
+		numOfBits = r.Left()
+	}
+	return &BitReader{input: sliceBits(r.input, r.pos, r.pos+numOfBits), limit: numOfBits, base: r.BitOffset(), path: r.FieldPath(name), err: err, recorder: r.recorder}
+}
+
+//ReplaceInput replaces the bytes read by r with input of the same length, e.g. the section whose encrypted portion is decrypted
+func (r *BitReader) ReplaceInput(input []byte) {
+	if len(input)*8 != r.limit {
+		r.Fail("", "the replacing input has "+strconv.Itoa(len(input))+" bytes, while "+strconv.Itoa(r.limit/8)+" bytes are expected")
+		return
+	}
+	r.input = input
+}
+
+//Skip advances the reader by numOfBits bits without decoding them
+func (r *BitReader) Skip(name string, numOfBits int) {
+	if r.require(name, numOfBits) {
+		r.record(name, r.BitOffset(), numOfBits, sliceBits(r.input, r.pos, r.pos+numOfBits), "skipped")
+		r.pos += numOfBits
+	}
+}
+
+//Merge adopts the error of a sub reader and advances the reader by the bits the sub reader consumed
+func (r *BitReader) Merge(sub *BitReader) {
+	r.SetErr(sub.err)
+	if r.err == nil {
+		r.pos += sub.pos
+	}
+}
+
+//sliceBits returns the bits [start, end) of input, aligned to the first bit of the result
+func sliceBits(input []byte, start int, end int) []byte {
+	if end <= start {
+		return []byte{}
+	}
+	if start%8 == 0 {
+		return input[start/8 : (end+7)/8]
+	}
+	return subBits(input, start, end-start)
+}
+
+//subBits copies numOfBits bits of input from startBit, aligned to the first bit of the result and padded with 0 bits
+//The caller makes sure that the bits are within input
+func subBits(input []byte, startBit int, numOfBits int) []byte {
+	output := make([]byte, (numOfBits+7)/8)
+	for i := 0; i < numOfBits; i++ {
+		pos := startBit + i
+		if input[pos/8]&(0x80>>uint(pos%8)) != 0 {
+			output[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return output
+}
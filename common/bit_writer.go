@@ -0,0 +1,77 @@
+package common
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+//BitWriter accumulates values MSB first, it is the counterpart of BitReader used by DecodeFromRawBytes
+type BitWriter struct {
+	buf       []byte
+	numOfBits int
+}
+
+//WriteBits appends the lowest numOfBits bits of value
+func (w *BitWriter) WriteBits(value uint64, numOfBits int) {
+	for i := numOfBits - 1; i >= 0; i-- {
+		if w.numOfBits%8 == 0 {
+			w.buf = append(w.buf, 0x00)
+		}
+		if (value>>uint(i))&0x01 == 0x01 {
+			w.buf[w.numOfBits/8] |= 0x80 >> uint(w.numOfBits%8)
+		}
+		w.numOfBits++
+	}
+}
+
+//WriteBool appends a single bit flag
+func (w *BitWriter) WriteBool(value bool) {
+	if value {
+		w.WriteBits(1, 1)
+	} else {
+		w.WriteBits(0, 1)
+	}
+}
+
+//WriteReserved appends numOfBits reserved bits, which are always set to 1
+func (w *BitWriter) WriteReserved(numOfBits int) {
+	for i := 0; i < numOfBits; i++ {
+		w.WriteBits(1, 1)
+	}
+}
+
+//WriteBytes appends all bytes of input
+func (w *BitWriter) WriteBytes(input []byte) {
+	for _, b := range input {
+		w.WriteBits(uint64(b), 8)
+	}
+}
+
+//WriteHexString appends the bytes represented by hexStr
+func (w *BitWriter) WriteHexString(hexStr string) error {
+	tmpBytes, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return err
+	}
+	w.WriteBytes(tmpBytes)
+	return nil
+}
+
+//Len returns the number of bits written so far
+func (w *BitWriter) Len() int {
+	return w.numOfBits
+}
+
+//Bytes returns the written bits, it fails if the bits written are not byte aligned
+func (w *BitWriter) Bytes() ([]byte, error) {
+	if w.numOfBits%8 != 0 {
+		return nil, errors.New("Encode Error: The number of bits written(" + strconv.Itoa(w.numOfBits) + ") is not divisible by 8")
+	}
+	return w.buf, nil
+}
+
+//MissingFieldError is returned by EncodeToRawBytes when a field required by the current flags is nil
+func MissingFieldError(fieldName string) error {
+	return errors.New("Encode Error: Missing Field: " + fieldName)
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"encoding/hex"
+)
+
+//crc32MPEG2Table is the lookup table of CRC-32/MPEG-2 (polynomial 0x04C11DB7, no reflection)
+var crc32MPEG2Table = func() (table [256]uint32) {
+	for i := 0; i < 256; i++ {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = (crc << 1) ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+//CRC32MPEG2 calculates the CRC_32 defined in ISO/IEC 13818-1 Annex A, which is used by CRC_32 and E_CRC_32 of splice_info_section
+func CRC32MPEG2(input []byte) uint32 {
+	crc := uint32(0xffffffff)
+	for _, b := range input {
+		crc = (crc << 8) ^ crc32MPEG2Table[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+//CRC32MPEG2InHex is CRC32MPEG2 with the result formatted in the same way as CRC32InHex
+func CRC32MPEG2InHex(input []byte) string {
+	crc := CRC32MPEG2(input)
+	return hex.EncodeToString([]byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)})
+}
+
+//CRC32MismatchError is returned by DecodeFromRawBytes when the CRC_32(or E_CRC_32) carried by the section does not match the calculated one
+type CRC32MismatchError struct {
+	FieldName  string //crc_32 or e_crc_32
+	Expected   string //value carried in the section
+	Calculated string
+}
+
+func (e *CRC32MismatchError) Error() string {
+	return "CRC32 Mismatch: " + e.FieldName + " in section is " + e.Expected + ", calculated value is " + e.Calculated
+}
+
+//DecodeOptions controls the behaviour of DecodeFromRawBytesWithOptions
+type DecodeOptions struct {
+	//IgnoreCRC32Mismatch decodes the section even if CRC_32 is wrong, the mismatch is reported by SCTE35.CRC32Mismatch()
+	IgnoreCRC32Mismatch bool
+
+	//Lenient keeps decoding after an error, the partial result is kept in the object and all problems are returned as DecodeErrors
+	//A splice descriptor which cannot be decoded is kept in UndecodedBytesInHex and skipped by its descriptor_length,
+	//a CRC_32 mismatch is reported as a problem as well
+	Lenient bool
+
+	//Keys decrypts the encrypted portion of a section with encrypted_packet set, by the control word of cw_index and encryption_algorithm
+	//E_CRC_32 is verified against the decrypted portion. Without Keys, decoding an encrypted section fails at splice_command_type
+	Keys KeyProvider
+
+	//Plaintext tells that the encrypted portion of input is not encrypted, e.g. the output of EncodeToRawBytes without EncodeOptions.Keys
+	Plaintext bool
+
+	//RecordFields records every field read(including reserved bits) with its bit offset, raw bits and value, see SCTE35.Fields()
+	RecordFields bool
+}
+
+//CRC32Mismatch returns the CRC_32 mismatch found by the last decode, nil if CRC_32 is valid
+func (scte35 *SCTE35) CRC32Mismatch() *CRC32MismatchError {
+	return scte35.crc32Mismatch
+}
+
+//VerifyCRC32 checks CRC_32 of the raw splice_info_section, the result is also recorded for CRC32Mismatch()
+func (scte35 *SCTE35) VerifyCRC32(input []byte) error {
+	scte35.crc32Mismatch = nil
+	if len(input) < 4 {
+		return nil
+	}
+
+	calculated := CRC32MPEG2InHex(input[:len(input)-4])
+	expected := hex.EncodeToString(input[len(input)-4:])
+	if calculated != expected {
+		scte35.crc32Mismatch = &CRC32MismatchError{FieldName: "crc_32", Expected: expected, Calculated: calculated}
+		return scte35.crc32Mismatch
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package common
+
+import (
+	"crypto/cipher"
+	"crypto/des"
+	"errors"
+	"strconv"
+)
+
+//encryption_algorithm of splice_info_section, see SCTE 35 11.3
+const (
+	EncryptionNone      = 0
+	EncryptionDESECB    = 1
+	EncryptionDESCBC    = 2
+	EncryptionTripleDES = 3 //Triple DES EDE3, ECB mode
+)
+
+//encryptedPortionOffset is the byte offset of splice_command_type, where the encrypted portion starts
+const encryptedPortionOffset = 13
+
+//KeyProvider returns the control words of encrypted splice_info_sections, see DecodeOptions.Keys and EncodeOptions.Keys
+type KeyProvider interface {
+	//ControlWord returns the key of cwIndex, 8 bytes for DES, 24 bytes(or 16 bytes of keying option 2) for Triple DES
+	ControlWord(cwIndex uint8) ([]byte, error)
+}
+
+//KeyTable is a KeyProvider of fixed control words indexed by cw_index
+type KeyTable map[uint8][]byte
+
+//ControlWord returns the control word of cwIndex, an error is returned if there is none
+func (table KeyTable) ControlWord(cwIndex uint8) ([]byte, error) {
+	key, ok := table[cwIndex]
+	if !ok {
+		return nil, errors.New("no control word for cw_index " + strconv.Itoa(int(cwIndex)))
+	}
+	return key, nil
+}
+
+//EncodeOptions controls the behaviour of EncodeToRawBytesWithOptions
+type EncodeOptions struct {
+	//Keys encrypts the section if encrypted_packet is set, by the control word of cw_index and encryption_algorithm
+	//alignment_stuffing is replaced by 0xFF bytes if it does not align the encrypted portion to 8 bytes
+	//Without Keys, the encrypted portion is written in plaintext
+	Keys KeyProvider
+}
+
+//DecryptSection returns input with the encrypted portion(from splice_command_type to E_CRC_32) decrypted by the control word of cw_index
+//input is returned as is if encryption_algorithm is 0(no encryption)
+func (scte35 *SCTE35) DecryptSection(input []byte, keys KeyProvider) ([]byte, error) {
+	if scte35.EncryptionAlgorithm == EncryptionNone {
+		return input, nil
+	}
+	if len(input) < encryptedPortionOffset+4 {
+		return nil, errors.New("Decrypt Error: the section is too short to have an encrypted portion")
+	}
+
+	blockMode, err := scte35.blockMode(keys, false)
+	if err != nil {
+		return nil, errors.New("Decrypt Error: " + err.Error())
+	}
+	portion := input[encryptedPortionOffset : len(input)-4]
+	if len(portion)%blockMode.BlockSize() != 0 {
+		return nil, errors.New("Decrypt Error: the encrypted portion(" + strconv.Itoa(len(portion)) + " bytes) is not a multiple of " + strconv.Itoa(blockMode.BlockSize()) + " bytes")
+	}
+
+	output := append([]byte{}, input...)
+	blockMode.CryptBlocks(output[encryptedPortionOffset:len(input)-4], portion)
+	return output, nil
+}
+
+//EncryptSection returns section with the encrypted portion encrypted by the control word of cw_index, section ends with E_CRC_32 and has no CRC_32 yet
+func (scte35 *SCTE35) EncryptSection(section []byte, keys KeyProvider) ([]byte, error) {
+	if scte35.EncryptionAlgorithm == EncryptionNone {
+		return section, nil
+	}
+	if len(section) < encryptedPortionOffset {
+		return nil, errors.New("Encrypt Error: the section is too short to have an encrypted portion")
+	}
+
+	blockMode, err := scte35.blockMode(keys, true)
+	if err != nil {
+		return nil, errors.New("Encrypt Error: " + err.Error())
+	}
+	portion := section[encryptedPortionOffset:]
+	if len(portion)%blockMode.BlockSize() != 0 {
+		return nil, errors.New("Encrypt Error: the encrypted portion(" + strconv.Itoa(len(portion)) + " bytes) is not a multiple of " + strconv.Itoa(blockMode.BlockSize()) + " bytes")
+	}
+
+	output := append([]byte{}, section...)
+	blockMode.CryptBlocks(output[encryptedPortionOffset:], portion)
+	return output, nil
+}
+
+//EncryptionStuffing returns the alignment_stuffing making the encrypted portion a multiple of the DES block size
+//stuffing is kept if it already does, otherwise it is replaced by 0xFF bytes, numOfOtherBytes is the size of the encrypted portion without stuffing
+func EncryptionStuffing(stuffing []byte, numOfOtherBytes int) []byte {
+	if (numOfOtherBytes+len(stuffing))%des.BlockSize == 0 {
+		return stuffing
+	}
+	stuffing = make([]byte, (des.BlockSize-numOfOtherBytes%des.BlockSize)%des.BlockSize)
+	for i := range stuffing {
+		stuffing[i] = 0xFF
+	}
+	return stuffing
+}
+
+//VerifyECRC32 checks E_CRC_32 of the decrypted splice_info_section, the result is also recorded for CRC32Mismatch()
+//E_CRC_32 covers the decrypted portion from splice_command_type to alignment_stuffing
+func (scte35 *SCTE35) VerifyECRC32(decrypted []byte) error {
+	if !scte35.EncryptedPacket || scte35.ECRC32InHex == nil || len(decrypted) < encryptedPortionOffset+8 {
+		return nil
+	}
+
+	calculated := CRC32MPEG2InHex(decrypted[encryptedPortionOffset : len(decrypted)-8])
+	if calculated != *scte35.ECRC32InHex {
+		scte35.crc32Mismatch = &CRC32MismatchError{FieldName: "e_crc_32", Expected: *scte35.ECRC32InHex, Calculated: calculated}
+		return scte35.crc32Mismatch
+	}
+	return nil
+}
+
+//blockMode returns the cipher of encryption_algorithm with the control word of cw_index
+//DES-CBC uses an initialization vector of 0
+func (scte35 *SCTE35) blockMode(keys KeyProvider, encrypt bool) (cipher.BlockMode, error) {
+	if keys == nil {
+		return nil, errors.New("encrypted_packet is set but no key provider is given")
+	}
+	algorithm := scte35.EncryptionAlgorithm
+	if algorithm != EncryptionDESECB && algorithm != EncryptionDESCBC && algorithm != EncryptionTripleDES {
+		return nil, errors.New("unsupported encryption_algorithm " + strconv.Itoa(int(algorithm)))
+	}
+
+	key, err := keys.ControlWord(scte35.CWIndex)
+	if err != nil {
+		return nil, err
+	}
+	var block cipher.Block
+	if algorithm == EncryptionTripleDES {
+		if len(key) == 16 {
+			key = append(append([]byte{}, key...), key[:8]...) //keying option 2, K3 = K1
+		}
+		block, err = des.NewTripleDESCipher(key)
+	} else {
+		block, err = des.NewCipher(key)
+	}
+	if err != nil {
+		return nil, errors.New("invalid control word of cw_index " + strconv.Itoa(int(scte35.CWIndex)) + ": " + err.Error())
+	}
+
+	if algorithm == EncryptionDESCBC {
+		iv := make([]byte, des.BlockSize)
+		if encrypt {
+			return cipher.NewCBCEncrypter(block, iv), nil
+		}
+		return cipher.NewCBCDecrypter(block, iv), nil
+	}
+	return ecb{block: block, encrypt: encrypt}, nil
+}
+
+//ecb is the electronic codebook mode, which is not provided by crypto/cipher
+type ecb struct {
+	block   cipher.Block
+	encrypt bool
+}
+
+func (mode ecb) BlockSize() int {
+	return mode.block.BlockSize()
+}
+
+func (mode ecb) CryptBlocks(dst, src []byte) {
+	size := mode.block.BlockSize()
+	for i := 0; i+size <= len(src); i += size {
+		if mode.encrypt {
+			mode.block.Encrypt(dst[i:i+size], src[i:i+size])
+		} else {
+			mode.block.Decrypt(dst[i:i+size], src[i:i+size])
+		}
+	}
+}
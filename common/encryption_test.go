@@ -0,0 +1,108 @@
+package common_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	schema_2017 "github.com/chanyk-joseph/scte35_decoder/2017"
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//encryptionVectors are the splice_insert of the README(splice_event_id 1, out of network) with 5 bytes of alignment_stuffing,
+//encrypted by OpenSSL(des-ecb, des-cbc with an IV of 0, des-ede3-ecb and des-ede-ecb) rather than by this package
+var encryptionVectors = []struct {
+	name       string
+	cwIndex    uint8
+	key        string
+	ciphertext string
+	plaintext  string //the same section decrypted, CRC_32 is the one of ciphertext
+}{
+	{
+		name:       "DES-ECB",
+		cwIndex:    1,
+		key:        "0123456789abcdef",
+		ciphertext: "fc302e00820000000001fff014df05a261f191a8f2a02b2d12104940f670abdff705070b10c3784dd68da1627ab02eea79",
+		plaintext:  "fc302e00820000000001fff01405000000017feffe2d142b00fe0123d308000101010000ffffffffff189560f5b02eea79",
+	},
+	{
+		name:       "DES-CBC",
+		cwIndex:    2,
+		key:        "0123456789abcdef",
+		ciphertext: "fc302e00840000000002fff014df05a261f191a8f214114304cf765feeb3251007edbbfaa21c25f051bd2fdfc1791181c9",
+		plaintext:  "fc302e00840000000002fff01405000000017feffe2d142b00fe0123d308000101010000ffffffffff189560f5791181c9",
+	},
+	{
+		name:       "Triple DES, keying option 1",
+		cwIndex:    3,
+		key:        "0123456789abcdeffedcba987654321089abcdef01234567",
+		ciphertext: "fc302e00860000000003fff014cc9a6d429ebdf5f1773140f76934f1167f485d29de2e25adc98bf995a0fd741a381f470a",
+		plaintext:  "fc302e00860000000003fff01405000000017feffe2d142b00fe0123d308000101010000ffffffffff189560f5381f470a",
+	},
+	{
+		name:       "Triple DES, keying option 2",
+		cwIndex:    4,
+		key:        "0123456789abcdeffedcba9876543210",
+		ciphertext: "fc302e00860000000004fff01405affe7a2a541d8744df8afcafdb937c938a0a34f2c9b75bfc21d96cebbe22630186c7f7",
+		plaintext:  "fc302e00860000000004fff01405000000017feffe2d142b00fe0123d308000101010000ffffffffff189560f50186c7f7",
+	},
+}
+
+//expectedECRC32 is CRC_32 of the plaintext from splice_command_type to alignment_stuffing
+const expectedECRC32 = "189560f5"
+
+func TestDecryptKnownAnswers(t *testing.T) {
+	for _, vector := range encryptionVectors {
+		ciphertext, _ := hex.DecodeString(vector.ciphertext)
+		plaintext, _ := hex.DecodeString(vector.plaintext)
+		key, _ := hex.DecodeString(vector.key)
+
+		scte35 := &schema_2017.SCTE35{}
+		if _, err := scte35.DecodeFromRawBytesWithOptions(ciphertext, common.DecodeOptions{Keys: common.KeyTable{vector.cwIndex: key}}); err != nil {
+			t.Errorf("%s: unable to decode: %v", vector.name, err)
+			continue
+		}
+		if !bytes.Equal(scte35.RawBytes(), plaintext) {
+			t.Errorf("%s: decrypted to %x, expected %x", vector.name, scte35.RawBytes(), plaintext)
+		}
+		if scte35.ECRC32InHex == nil || *scte35.ECRC32InHex != expectedECRC32 {
+			t.Errorf("%s: e_crc_32 is %v, expected %s", vector.name, scte35.ECRC32InHex, expectedECRC32)
+		}
+		if scte35.SpliceInsert == nil || scte35.SpliceInsert.SpliceEventID != 1 {
+			t.Errorf("%s: splice_insert is not decrypted: %s", vector.name, scte35.JSON())
+		}
+	}
+}
+
+func TestEncryptKnownAnswers(t *testing.T) {
+	for _, vector := range encryptionVectors {
+		ciphertext, _ := hex.DecodeString(vector.ciphertext)
+		plaintext, _ := hex.DecodeString(vector.plaintext)
+		key, _ := hex.DecodeString(vector.key)
+
+		scte35 := &schema_2017.SCTE35{}
+		if _, err := scte35.DecodeFromRawBytesWithOptions(plaintext, common.DecodeOptions{Plaintext: true, IgnoreCRC32Mismatch: true}); err != nil {
+			t.Errorf("%s: unable to decode the plaintext: %v", vector.name, err)
+			continue
+		}
+		output, err := scte35.EncodeToRawBytesWithOptions(common.EncodeOptions{Keys: common.KeyTable{vector.cwIndex: key}})
+		if err != nil {
+			t.Errorf("%s: unable to encode: %v", vector.name, err)
+			continue
+		}
+		if !bytes.Equal(output, ciphertext) {
+			t.Errorf("%s: encrypted to %x, expected %x", vector.name, output, ciphertext)
+		}
+	}
+}
+
+func TestDecryptWithWrongKey(t *testing.T) {
+	vector := encryptionVectors[0]
+	ciphertext, _ := hex.DecodeString(vector.ciphertext)
+
+	scte35 := &schema_2017.SCTE35{}
+	_, err := scte35.DecodeFromRawBytesWithOptions(ciphertext, common.DecodeOptions{Keys: common.KeyTable{vector.cwIndex: []byte("8bytekey")}, IgnoreCRC32Mismatch: true})
+	if err == nil && scte35.CRC32Mismatch() == nil {
+		t.Errorf("decrypting with a wrong key is neither a parse error nor an e_crc_32 mismatch")
+	}
+}
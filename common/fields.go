@@ -0,0 +1,120 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Field is a field read by BitReader, recorded if DecodeOptions.RecordFields is set
+type Field struct {
+	Path      string `json:"path"`       //structure path of the field, e.g. splice_insert.splice_time.pts_time
+	BitOffset int    `json:"bit_offset"` //from the start of the section
+	BitLength int    `json:"bit_length"`
+	Raw       []byte `json:"-"`                   //bits of the field, aligned to the first bit of Raw
+	Value     string `json:"value"`               //interpreted value, empty for reserved bits
+	Reserved  bool   `json:"reserved,omitempty"`  //true for reserved bits
+	Violation string `json:"violation,omitempty"` //e.g. reserved bits which are not all 1, empty if the field is fine
+}
+
+//BitString returns the bits of the field, e.g. "0110"
+func (f Field) BitString() string {
+	var b strings.Builder
+	for i := 0; i < f.BitLength; i++ {
+		if f.Raw[i/8]&(0x80>>uint(i%8)) != 0 {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+//Fields returns the fields recorded by the last decode with DecodeOptions.RecordFields, in the order of their bit offsets
+//If the decode failed, the fields read before the failure are returned
+func (scte35 *SCTE35) Fields() []Field {
+	return scte35.fields
+}
+
+//TicksFieldNames are the fields in 90 kHz ticks, their values are also recorded as durations(and written in seconds by the text output of the CLI)
+var TicksFieldNames = map[string]bool{
+	"pts_adjustment":        true,
+	"pts_time":              true,
+	"pts_offset":            true,
+	"duration":              true,
+	"segmentation_duration": true,
+}
+
+//fieldRecorder collects the fields read by a BitReader and its sub readers
+type fieldRecorder struct {
+	fields []Field
+}
+
+//Record starts recording the fields read by the reader and its sub readers, see Fields
+func (r *BitReader) Record() {
+	r.recorder = &fieldRecorder{}
+}
+
+//Fields returns the fields recorded so far, nil if Record is not called
+func (r *BitReader) Fields() []Field {
+	if r.recorder == nil {
+		return nil
+	}
+	return r.recorder.fields
+}
+
+func (r *BitReader) record(name string, bitOffset int, numOfBits int, raw []byte, value string) {
+	if r.recorder != nil {
+		r.recorder.fields = append(r.recorder.fields, Field{Path: r.FieldPath(name), BitOffset: bitOffset, BitLength: numOfBits, Raw: raw, Value: value})
+	}
+}
+
+func (r *BitReader) recordUint(name string, bitOffset int, numOfBits int, raw []byte, value uint64) {
+	if r.recorder == nil {
+		return
+	}
+	text := strconv.FormatUint(value, 10)
+	if nameOf, ok := nameOfJSONFields[name]; ok && nameOf(value) != "" {
+		text += " (" + nameOf(value) + ")"
+	}
+	if TicksFieldNames[name] {
+		text += " (" + TicksToDuration(value).String() + ")"
+	}
+	r.record(name, bitOffset, numOfBits, raw, text)
+}
+
+func (r *BitReader) recordReserved(bitOffset int, numOfBits int, raw []byte) {
+	if r.recorder != nil {
+		r.recorder.fields = append(r.recorder.fields, reservedField(r.FieldPath("reserved"), bitOffset, numOfBits, raw))
+	}
+}
+
+func reservedField(path string, bitOffset int, numOfBits int, raw []byte) Field {
+	field := Field{Path: path, BitOffset: bitOffset, BitLength: numOfBits, Raw: raw, Reserved: true}
+	if strings.Contains(field.BitString(), "0") {
+		field.Violation = "reserved bits must be 1"
+	}
+	return field
+}
+
+//carve records field which is carved out of reserved bits by later revisions of the standard, see BitReader.Peek
+//The reserved field recorded for its bits is split around it
+func (recorder *fieldRecorder) carve(field Field) {
+	for i, reserved := range recorder.fields {
+		end := reserved.BitOffset + reserved.BitLength
+		if !reserved.Reserved || field.BitOffset < reserved.BitOffset || field.BitOffset+field.BitLength > end {
+			continue
+		}
+
+		fields := append([]Field{}, recorder.fields[:i]...)
+		if n := field.BitOffset - reserved.BitOffset; n > 0 {
+			fields = append(fields, reservedField(reserved.Path, reserved.BitOffset, n, subBits(reserved.Raw, 0, n)))
+		}
+		fields = append(fields, field)
+		if n := end - (field.BitOffset + field.BitLength); n > 0 {
+			fields = append(fields, reservedField(reserved.Path, end-n, n, subBits(reserved.Raw, reserved.BitLength-n, n)))
+		}
+		recorder.fields = append(fields, recorder.fields[i+1:]...)
+		return
+	}
+	recorder.fields = append(recorder.fields, field)
+}
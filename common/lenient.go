@@ -0,0 +1,77 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+//DecodeErrors is the list of problems found by a lenient decode, see DecodeOptions.Lenient
+type DecodeErrors []error
+
+func (errs DecodeErrors) Error() string {
+	errMsgs := make([]string, len(errs))
+	for i, err := range errs {
+		errMsgs[i] = err.Error()
+	}
+	return strconv.Itoa(len(errs)) + " problem(s) found: " + strings.Join(errMsgs, "; ")
+}
+
+//Problems returns the problems found by the last lenient decode, nil if the section is decoded without any problem
+func (scte35 *SCTE35) Problems() DecodeErrors {
+	return scte35.problems
+}
+
+//FinishDecode completes DecodeFromRawBytesWithOptions of the schema packages once the whole input is read by r
+//In lenient mode, the error of r and CRC_32 mismatch are added to problems, which are returned if not empty
+func (scte35 *SCTE35) FinishDecode(input []byte, r *BitReader, problems DecodeErrors, opts DecodeOptions) (numOfParsedBits int, err error) {
+	scte35.problems = nil
+	scte35.fields = r.Fields()
+	if r.Err() != nil {
+		if !opts.Lenient {
+			return 0, r.Err()
+		}
+		if len(problems) == 0 || problems[len(problems)-1].Error() != r.Err().Error() {
+			problems = append(problems, r.Err())
+		}
+	} else {
+		//r reads the decrypted section if the section is encrypted, while CRC_32 covers the transmitted one
+		scte35.SetRawBytes(r.input)
+		err = scte35.VerifyCRC32(input)
+		if err == nil {
+			err = scte35.VerifyECRC32(r.input)
+		}
+		if err != nil {
+			if opts.Lenient {
+				problems = append(problems, err)
+			} else if !opts.IgnoreCRC32Mismatch {
+				return 0, err
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		scte35.problems = problems
+		return r.Pos(), problems
+	}
+	return r.Pos(), nil
+}
+
+//DecodeAsUndecoded reads the descriptor at r without interpreting the bytes following identifier, which are kept in UndecodedBytesInHex
+//It is used by lenient decoding to keep a descriptor which failed to decode with cause
+func (spliceDesc *SpliceDescriptor) DecodeAsUndecoded(r *BitReader, cause error) {
+	spliceDesc.SpliceDescriptorTag = SpliceDescriptorTag(r.Uint8("splice_descriptor_tag"))
+	spliceDesc.DescriptorLength = r.Uint8("descriptor_length")
+	if r.Err() == nil && spliceDesc.DescriptorLength < 4 {
+		r.Fail("descriptor_length", "descriptor_length("+strconv.Itoa(int(spliceDesc.DescriptorLength))+") is less than the 4 bytes of identifier")
+		return
+	}
+
+	body := r.Sub("", int(spliceDesc.DescriptorLength)*8)
+	spliceDesc.Identifier = body.Uint32("identifier")
+	undecodedBytesInHex := body.HexString("undecoded_bytes", body.Left()/8)
+	spliceDesc.UndecodedBytesInHex = &undecodedBytesInHex
+	r.Merge(body)
+
+	decodeError := cause.Error()
+	spliceDesc.DecodeError = &decodeError
+}
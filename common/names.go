@@ -0,0 +1,284 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+//SpliceCommandType is splice_command_type of splice_info_section
+type SpliceCommandType byte
+
+func (t SpliceCommandType) String() string {
+	switch t {
+	case 0x00:
+		return "splice_null"
+	case 0x04:
+		return "splice_schedule"
+	case 0x05:
+		return "splice_insert"
+	case 0x06:
+		return "time_signal"
+	case 0x07:
+		return "bandwidth_reservation"
+	case 0xff:
+		return "private_command"
+	}
+	return "reserved"
+}
+
+//SpliceDescriptorTag is splice_descriptor_tag of splice descriptors whose identifier is CUEI
+type SpliceDescriptorTag byte
+
+func (t SpliceDescriptorTag) String() string {
+	switch t {
+	case 0x00:
+		return "avail_descriptor"
+	case 0x01:
+		return "DTMF_descriptor"
+	case 0x02:
+		return "segmentation_descriptor"
+	case 0x03:
+		return "time_descriptor"
+	case 0x04:
+		return "audio_descriptor"
+	}
+	return "reserved"
+}
+
+//SegmentationTypeID is segmentation_type_id of segmentation descriptor
+type SegmentationTypeID uint8
+
+var segmentationTypeIDNames = map[SegmentationTypeID]string{
+	0x00: "Not Indicated",
+	0x01: "Content Identification",
+	0x02: "Call Ad Server",
+	0x10: "Program Start",
+	0x11: "Program End",
+	0x12: "Program Early Termination",
+	0x13: "Program Breakaway",
+	0x14: "Program Resumption",
+	0x15: "Program Runover Planned",
+	0x16: "Program Runover Unplanned",
+	0x17: "Program Overlap Start",
+	0x18: "Program Blackout Override",
+	0x19: "Program Join",
+	0x20: "Chapter Start",
+	0x21: "Chapter End",
+	0x22: "Break Start",
+	0x23: "Break End",
+	0x24: "Opening Credit Start",
+	0x25: "Opening Credit End",
+	0x26: "Closing Credit Start",
+	0x27: "Closing Credit End",
+	0x30: "Provider Advertisement Start",
+	0x31: "Provider Advertisement End",
+	0x32: "Distributor Advertisement Start",
+	0x33: "Distributor Advertisement End",
+	0x34: "Provider Placement Opportunity Start",
+	0x35: "Provider Placement Opportunity End",
+	0x36: "Distributor Placement Opportunity Start",
+	0x37: "Distributor Placement Opportunity End",
+	0x38: "Provider Overlay Placement Opportunity Start",
+	0x39: "Provider Overlay Placement Opportunity End",
+	0x3A: "Distributor Overlay Placement Opportunity Start",
+	0x3B: "Distributor Overlay Placement Opportunity End",
+	0x3C: "Provider Promo Start",
+	0x3D: "Provider Promo End",
+	0x3E: "Distributor Promo Start",
+	0x3F: "Distributor Promo End",
+	0x40: "Unscheduled Event Start",
+	0x41: "Unscheduled Event End",
+	0x42: "Alternate Content Opportunity Start",
+	0x43: "Alternate Content Opportunity End",
+	0x44: "Provider Ad Block Start",
+	0x45: "Provider Ad Block End",
+	0x46: "Distributor Ad Block Start",
+	0x47: "Distributor Ad Block End",
+	0x50: "Network Start",
+	0x51: "Network End",
+}
+
+func (t SegmentationTypeID) String() string {
+	if name, ok := segmentationTypeIDNames[t]; ok {
+		return name
+	}
+	return "Reserved"
+}
+
+//SegmentationUpidType is segmentation_upid_type of segmentation descriptor
+type SegmentationUpidType byte
+
+var segmentationUpidTypeNames = map[SegmentationUpidType]string{
+	0x00: "Not Used",
+	0x01: "User Defined",
+	0x02: "ISCI",
+	0x03: "Ad-ID",
+	0x04: "UMID",
+	0x05: "ISAN (Deprecated)",
+	0x06: "ISAN",
+	0x07: "TID",
+	0x08: "TI",
+	0x09: "ADI",
+	0x0A: "EIDR",
+	0x0B: "ATSC Content Identifier",
+	0x0C: "MPU",
+	0x0D: "MID",
+	0x0E: "ADS Information",
+	0x0F: "URI",
+	0x10: "UUID",
+	0x11: "SCR",
+}
+
+func (t SegmentationUpidType) String() string {
+	if name, ok := segmentationUpidTypeNames[t]; ok {
+		return name
+	}
+	return "Reserved"
+}
+
+//DeviceRestrictions is device_restrictions of segmentation descriptor, 2 bits
+type DeviceRestrictions uint8
+
+func (r DeviceRestrictions) String() string {
+	switch r {
+	case 0x00:
+		return "Restrict Group 0"
+	case 0x01:
+		return "Restrict Group 1"
+	case 0x02:
+		return "Restrict Group 2"
+	case 0x03:
+		return "None"
+	}
+	return "Invalid"
+}
+
+//nameOfJSONFields maps the JSON keys of enum fields to the functions naming their values, an empty name is not written
+var nameOfJSONFields = map[string]func(uint64) string{
+	"splice_command_type": func(v uint64) string { return SpliceCommandType(v).String() },
+	"splice_descriptor_tag": func(v uint64) string {
+		//the meaning of other tags depends on identifier, they are either reserved(CUEI) or private
+		if v > 0x04 {
+			return ""
+		}
+		return SpliceDescriptorTag(v).String()
+	},
+	"segmentation_type_id":   func(v uint64) string { return SegmentationTypeID(v).String() },
+	"segmentation_upid_type": func(v uint64) string { return SegmentationUpidType(v).String() },
+	"device_restrictions":    func(v uint64) string { return DeviceRestrictions(v).String() },
+}
+
+//AddNamesToJSON inserts "<key>_name" after every enum field(e.g. splice_command_type) of the JSON input, the order of other fields is kept
+func AddNamesToJSON(input []byte) ([]byte, error) {
+	var output bytes.Buffer
+	var pendingName string
+
+	type container struct {
+		isObject  bool
+		count     int
+		expectKey bool
+	}
+	var stack []*container
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var top *container
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			output.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && stack[len(stack)-1].isObject {
+				stack[len(stack)-1].expectKey = true
+			}
+			continue
+		}
+
+		if top != nil {
+			if top.isObject && top.expectKey {
+				if top.count > 0 {
+					output.WriteByte(',')
+				}
+				top.count++
+			} else if !top.isObject {
+				if top.count > 0 {
+					output.WriteByte(',')
+				}
+				top.count++
+			}
+		}
+
+		if top != nil && top.isObject && top.expectKey {
+			key := token.(string)
+			keyBytes, _ := json.Marshal(key)
+			output.Write(keyBytes)
+			output.WriteByte(':')
+			top.expectKey = false
+			pendingName = key
+			continue
+		}
+
+		switch value := token.(type) {
+		case json.Delim:
+			output.WriteByte(byte(value))
+			stack = append(stack, &container{isObject: value == '{', expectKey: value == '{'})
+			pendingName = ""
+			continue
+		case json.Number:
+			output.WriteString(value.String())
+			if nameOf, ok := nameOfJSONFields[pendingName]; ok && top != nil && top.isObject {
+				if v, err := strconv.ParseUint(value.String(), 10, 64); err == nil && nameOf(v) != "" {
+					nameBytes, _ := json.Marshal(nameOf(v))
+					output.WriteString(`,"` + pendingName + `_name":`)
+					output.Write(nameBytes)
+				}
+			}
+		default:
+			valueBytes, _ := json.Marshal(value)
+			output.Write(valueBytes)
+		}
+		pendingName = ""
+		if top != nil && top.isObject {
+			top.expectKey = true
+		}
+	}
+
+	return output.Bytes(), nil
+}
+
+//IndentedJSON marshals v and indents it in the same way as the JSON() of the schema packages
+//If withNames is true, names are added next to the enum fields by AddNamesToJSON
+func IndentedJSON(v interface{}, withNames bool, indent ...string) (result string, err error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	if withNames {
+		if buf, err = AddNamesToJSON(buf); err != nil {
+			return "", err
+		}
+	}
+
+	if len(indent) > 0 {
+		var indented bytes.Buffer
+		if err = json.Indent(&indented, buf, "", indent[0]); err != nil {
+			return "", err
+		}
+		buf = indented.Bytes()
+	}
+	return string(buf), nil
+}
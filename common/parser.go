@@ -2,24 +2,25 @@ package common
 
 type Parser interface {
 	DecodeFromRawBytes([]byte) (int, error)
+	EncodeToRawBytes() ([]byte, error)
 	DecodeFromJSON(string) error
 	JSON(...string) string
 	SchemaVersion() string
 }
 
 type SCTE35 struct {
-	TableID                uint8  `json:"table_id"`
-	SectionSyntaxIndicator bool   `json:"section_syntax_indicator"`
-	PrivateIndicator       bool   `json:"private_indicator"`
-	SectionLength          uint16 `json:"section_length"` // 12 bits
-	ProtocolVersion        uint8  `json:"protocol_version"`
-	EncryptedPacket        bool   `json:"encrypted_packet"`
-	EncryptionAlgorithm    byte   `json:"encryption_algorithm"` // 6 bits
-	PTSAdjustment          uint64 `json:"pts_adjustment"`       // 33 bits
-	CWIndex                uint8  `json:"cw_index"`
-	Tier                   uint16 `json:"tier"`                  // 12 bits
-	SpliceCommandLength    uint16 `json:"splice_command_length"` // 12 bits
-	SpliceCommandType      byte   `json:"splice_command_type"`
+	TableID                uint8             `json:"table_id"`
+	SectionSyntaxIndicator bool              `json:"section_syntax_indicator"`
+	PrivateIndicator       bool              `json:"private_indicator"`
+	SectionLength          uint16            `json:"section_length"` // 12 bits
+	ProtocolVersion        uint8             `json:"protocol_version"`
+	EncryptedPacket        bool              `json:"encrypted_packet"`
+	EncryptionAlgorithm    byte              `json:"encryption_algorithm"` // 6 bits
+	PTSAdjustment          uint64            `json:"pts_adjustment"`       // 33 bits
+	CWIndex                uint8             `json:"cw_index"`
+	Tier                   uint16            `json:"tier"`                  // 12 bits
+	SpliceCommandLength    uint16            `json:"splice_command_length"` // 12 bits
+	SpliceCommandType      SpliceCommandType `json:"splice_command_type"`
 
 	DescriptorLoopLength uint16             `json:"descriptor_loop_length"`
 	SpliceDescriptors    []SpliceDescriptor `json:"splice_descriptors"`
@@ -27,12 +28,24 @@ type SCTE35 struct {
 	AlignmentStuffingInHex *string `json:"alignment_stuffing_in_hex,omitempty"`
 	ECRC32InHex            *string `json:"e_crc_32_in_hex,omitempty"`
 	CRC32InHex             string  `json:"crc_32_in_hex"`
+
+	crc32Mismatch *CRC32MismatchError
+	rawBytes      []byte
+	problems      DecodeErrors
+	fields        []Field
 }
 
+//CUEIIdentifier is the identifier("CUEI") of all splice descriptors defined by SCTE35, it is not carried by the XML
+const CUEIIdentifier = 0x43554549
+
 type SpliceDescriptor struct {
-	SpliceDescriptorTag byte   `json:"splice_descriptor_tag"`
-	DescriptorLength    uint8  `json:"descriptor_length"`
-	Identifier          uint32 `json:"identifier"`
+	SpliceDescriptorTag SpliceDescriptorTag `json:"splice_descriptor_tag"`
+	DescriptorLength    uint8               `json:"descriptor_length"`
+	Identifier          uint32              `json:"identifier"`
 
 	PrivateByteInHex *string `json:"private_byte_in_hex,omitempty"`
+
+	//set by lenient decoding if the descriptor cannot be decoded, the bytes following identifier are kept as they are
+	UndecodedBytesInHex *string `json:"undecoded_bytes_in_hex,omitempty"`
+	DecodeError         *string `json:"decode_error,omitempty"`
 }
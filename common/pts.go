@@ -0,0 +1,85 @@
+package common
+
+import (
+	"time"
+)
+
+const (
+	//PTSClockRate is the frequency of PTS, pts_adjustment and all durations of SCTE35, i.e. 90 kHz
+	PTSClockRate = 90000
+	//PTSModulus is the wraparound point of the 33 bits PTS
+	PTSModulus = uint64(1) << 33
+)
+
+//TicksToDuration converts 90 kHz ticks to time.Duration
+func TicksToDuration(ticks uint64) time.Duration {
+	return time.Duration(ticks/PTSClockRate)*time.Second + time.Duration(ticks%PTSClockRate)*time.Second/PTSClockRate
+}
+
+//DurationToTicks converts time.Duration to 90 kHz ticks, negative duration is treated as 0
+func DurationToTicks(d time.Duration) uint64 {
+	if d < 0 {
+		return 0
+	}
+	return uint64(d/time.Second)*PTSClockRate + uint64(d%time.Second)*PTSClockRate/uint64(time.Second)
+}
+
+//AddPTS returns (pts + ticks) mod 2^33, e.g. the effective splice time is AddPTS(pts_time, pts_adjustment)
+func AddPTS(pts uint64, ticks uint64) uint64 {
+	return (pts%PTSModulus + ticks%PTSModulus) % PTSModulus
+}
+
+//PTSDiff returns a - b in ticks, assuming both PTS are less than half of the 33 bits range(about 13 hours) apart,
+//so that a rollover between them is handled, e.g. PTSDiff(0x10, 0x1FFFFFFF0) is 0x20
+func PTSDiff(a uint64, b uint64) int64 {
+	diff := (a%PTSModulus + PTSModulus - b%PTSModulus) % PTSModulus
+	if diff >= PTSModulus/2 {
+		return int64(diff) - int64(PTSModulus)
+	}
+	return int64(diff)
+}
+
+//PTSDiffDuration is PTSDiff converted to time.Duration
+func PTSDiffDuration(a uint64, b uint64) time.Duration {
+	diff := PTSDiff(a, b)
+	if diff < 0 {
+		return -TicksToDuration(uint64(-diff))
+	}
+	return TicksToDuration(uint64(diff))
+}
+
+//PTSBefore reports whether a is earlier than b, taking rollover into account
+func PTSBefore(a uint64, b uint64) bool {
+	return PTSDiff(a, b) < 0
+}
+
+//AdjustPTS applies pts_adjustment of the section to pts
+func (scte35 *SCTE35) AdjustPTS(pts uint64) uint64 {
+	return AddPTS(pts, scte35.PTSAdjustment)
+}
+
+//EffectivePTS returns (pts_time + ptsAdjustment) mod 2^33, ok is false if time_specified_flag is not set
+func (spliceTime *SpliceTime) EffectivePTS(ptsAdjustment uint64) (pts uint64, ok bool) {
+	if spliceTime == nil || !spliceTime.TimeSpecifiedFlag || spliceTime.PTSTime == nil {
+		return 0, false
+	}
+	return AddPTS(*spliceTime.PTSTime, ptsAdjustment), true
+}
+
+//TimeDuration returns duration of break_duration as time.Duration
+func (breakDuration *BreakDuration) TimeDuration() time.Duration {
+	return TicksToDuration(breakDuration.Duration)
+}
+
+//SegmentationTimeDuration returns segmentation_duration as time.Duration, ok is false if it is absent
+func (segDesc *SegmentationDescriptor) SegmentationTimeDuration() (d time.Duration, ok bool) {
+	if segDesc.SegmentationDuration == nil {
+		return 0, false
+	}
+	return TicksToDuration(*segDesc.SegmentationDuration), true
+}
+
+//PTSOffsetDuration returns pts_offset as time.Duration
+func (segComp *SegmentationComponent) PTSOffsetDuration() time.Duration {
+	return TicksToDuration(segComp.PTSOffset)
+}
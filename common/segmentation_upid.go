@@ -0,0 +1,685 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//Upid is the typed value of segmentation_upid, the concrete type is determined by segmentation_upid_type
+type Upid interface {
+	UpidType() SegmentationUpidType
+	DecodeFromRawBytes([]byte) (int, error)
+	EncodeToRawBytes() ([]byte, error)
+	String() string
+}
+
+//SegmentationUpid wraps the typed value of segmentation_upid in SegmentationDescriptor
+//It is derived from segmentation_upid_in_hex, the typed value in JSON is only used when segmentation_upid_in_hex is absent
+type SegmentationUpid struct {
+	Upid
+
+	raw json.RawMessage //the typed value in JSON, decoded once segmentation_upid_type is known
+}
+
+func (segUpid SegmentationUpid) MarshalJSON() ([]byte, error) {
+	return json.Marshal(segUpid.Upid)
+}
+
+//UnmarshalJSON keeps the typed value, which is decoded by DecodeSegmentationUpid() according to segmentation_upid_type
+func (segUpid *SegmentationUpid) UnmarshalJSON(bytes []byte) error {
+	segUpid.Upid = nil
+	segUpid.raw = append(json.RawMessage{}, bytes...)
+	return nil
+}
+
+//resolve decodes the typed value in JSON according to upidType, if it is not decoded yet
+func (segUpid *SegmentationUpid) resolve(upidType SegmentationUpidType) error {
+	if segUpid.Upid != nil || segUpid.raw == nil {
+		return nil
+	}
+	upid := NewUpid(upidType)
+	if upid == nil {
+		return errors.New("Parse Error: segmentation_upid_type " + strconv.Itoa(int(upidType)) + " has no typed segmentation_upid")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(segUpid.raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(upid); err != nil {
+		return errors.New("Parse Error: segmentation_upid is not a valid typed value of segmentation_upid_type " + strconv.Itoa(int(upidType)) + ": " + err.Error())
+	}
+	segUpid.Upid = upid
+	segUpid.raw = nil
+	return nil
+}
+
+//NewUpid returns an empty Upid of the given segmentation_upid_type, nil if the type carries no typed value
+func NewUpid(upidType SegmentationUpidType) Upid {
+	switch upidType {
+	case 0x01:
+		return &UserDefinedUpid{}
+	case 0x02:
+		return &ISCIUpid{}
+	case 0x03:
+		return &AdIDUpid{}
+	case 0x04:
+		return &UMIDUpid{}
+	case 0x05:
+		return &DeprecatedISANUpid{}
+	case 0x06:
+		return &ISANUpid{}
+	case 0x07:
+		return &TIDUpid{}
+	case 0x08:
+		return &TIUpid{}
+	case 0x09:
+		return &ADIUpid{}
+	case 0x0A:
+		return &EIDRUpid{}
+	case 0x0B:
+		return &ATSCContentIdentifierUpid{}
+	case 0x0C:
+		return &MPUUpid{}
+	case 0x0D:
+		return &MIDUpid{}
+	case 0x0E:
+		return &ADSInformationUpid{}
+	case 0x0F:
+		return &URIUpid{}
+	case 0x10:
+		return &UUIDUpid{}
+	case 0x11:
+		return &SCRUpid{}
+	}
+	return nil
+}
+
+//DecodeUpid parses the segmentation_upid bytes according to upidType, it returns nil without error if the type carries no typed value
+func DecodeUpid(upidType SegmentationUpidType, input []byte) (Upid, error) {
+	upid := NewUpid(upidType)
+	if upid == nil {
+		return nil, nil
+	}
+
+	numOfParsedBits, err := upid.DecodeFromRawBytes(input)
+	if err != nil {
+		return nil, err
+	}
+	if numOfParsedBits != len(input)*8 {
+		return nil, errors.New("Parse Error: segmentation_upid(type: " + strconv.Itoa(int(upidType)) + ") has " + strconv.Itoa(len(input)-numOfParsedBits/8) + " bytes left")
+	}
+	return upid, nil
+}
+
+//Upid returns the typed value of segmentation_upid_in_hex
+func (segDesc *SegmentationDescriptor) Upid() (Upid, error) {
+	if segDesc.SegmentationUpidType == nil {
+		return nil, nil
+	}
+
+	var upidBytes []byte
+	if segDesc.SegmentationUpidInHex != nil {
+		var err error
+		if upidBytes, err = hex.DecodeString(*segDesc.SegmentationUpidInHex); err != nil {
+			return nil, err
+		}
+	}
+	return DecodeUpid(*segDesc.SegmentationUpidType, upidBytes)
+}
+
+//DecodeSegmentationUpid refreshes SegmentationUpid from segmentation_upid_in_hex
+//Without segmentation_upid_in_hex, the typed value given in JSON is decoded according to segmentation_upid_type instead
+//A malformed upid is not treated as a parse error of the descriptor, the error is returned and SegmentationUpid is rejected by the encoder
+func (segDesc *SegmentationDescriptor) DecodeSegmentationUpid() error {
+	if segDesc.SegmentationUpidInHex == nil && segDesc.SegmentationUpid != nil {
+		if segDesc.SegmentationUpidType == nil {
+			return errors.New("Parse Error: segmentation_upid is given without segmentation_upid_type")
+		}
+		return segDesc.SegmentationUpid.resolve(*segDesc.SegmentationUpidType)
+	}
+	segDesc.SegmentationUpid = nil
+
+	upid, err := segDesc.Upid()
+	if err != nil {
+		return err
+	}
+	if upid != nil {
+		segDesc.SegmentationUpid = &SegmentationUpid{Upid: upid}
+	}
+	return nil
+}
+
+func checkUpidLength(name string, input []byte, expected int) error {
+	if len(input) != expected {
+		return errors.New("Parse Error: " + name + " requires " + strconv.Itoa(expected) + " bytes, got " + strconv.Itoa(len(input)))
+	}
+	return nil
+}
+
+func encodeFixedLengthString(name string, value string, expected int) ([]byte, error) {
+	if len(value) != expected {
+		return nil, errors.New("Encode Error: " + name + " requires " + strconv.Itoa(expected) + " characters: " + value)
+	}
+	return []byte(value), nil
+}
+
+//UserDefinedUpid | segmentation_upid_type = 0x01 (deprecated)
+type UserDefinedUpid struct {
+	PrivateDataInHex string `json:"private_data_in_hex"`
+}
+
+func (upid *UserDefinedUpid) UpidType() SegmentationUpidType { return 0x01 }
+func (upid *UserDefinedUpid) String() string                 { return upid.PrivateDataInHex }
+
+func (upid *UserDefinedUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	upid.PrivateDataInHex = hex.EncodeToString(input)
+	return len(input) * 8, nil
+}
+
+func (upid *UserDefinedUpid) EncodeToRawBytes() ([]byte, error) {
+	return hex.DecodeString(upid.PrivateDataInHex)
+}
+
+//ISCIUpid | segmentation_upid_type = 0x02 (deprecated), 8 characters
+type ISCIUpid struct {
+	ISCI string `json:"isci"`
+}
+
+func (upid *ISCIUpid) UpidType() SegmentationUpidType { return 0x02 }
+func (upid *ISCIUpid) String() string                 { return upid.ISCI }
+
+func (upid *ISCIUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("ISCI", input, 8); err != nil {
+		return 0, err
+	}
+	upid.ISCI = string(input)
+	return 64, nil
+}
+
+func (upid *ISCIUpid) EncodeToRawBytes() ([]byte, error) {
+	return encodeFixedLengthString("ISCI", upid.ISCI, 8)
+}
+
+//AdIDUpid | segmentation_upid_type = 0x03, 12 characters
+type AdIDUpid struct {
+	AdID string `json:"ad_id"`
+}
+
+func (upid *AdIDUpid) UpidType() SegmentationUpidType { return 0x03 }
+func (upid *AdIDUpid) String() string                 { return upid.AdID }
+
+func (upid *AdIDUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("Ad-ID", input, 12); err != nil {
+		return 0, err
+	}
+	upid.AdID = string(input)
+	return 96, nil
+}
+
+func (upid *AdIDUpid) EncodeToRawBytes() ([]byte, error) {
+	return encodeFixedLengthString("Ad-ID", upid.AdID, 12)
+}
+
+//UMIDUpid | segmentation_upid_type = 0x04, SMPTE 330 UMID of 32 bytes
+//UMID is represented as 8 groups of 8 hex digits separated by ".", e.g. 060A2B34.01010105.01010D20.13000000.D2C9036C.8F195343.AB7014D2.D718BFDA
+type UMIDUpid struct {
+	UMID string `json:"umid"`
+}
+
+func (upid *UMIDUpid) UpidType() SegmentationUpidType { return 0x04 }
+func (upid *UMIDUpid) String() string                 { return upid.UMID }
+
+func (upid *UMIDUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("UMID", input, 32); err != nil {
+		return 0, err
+	}
+	upid.UMID = groupHex(input, 8, ".")
+	return 256, nil
+}
+
+func (upid *UMIDUpid) EncodeToRawBytes() ([]byte, error) {
+	output, err := hex.DecodeString(strings.Replace(upid.UMID, ".", "", -1))
+	if err == nil && len(output) != 32 {
+		err = errors.New("Encode Error: UMID requires 32 bytes: " + upid.UMID)
+	}
+	return output, err
+}
+
+//DeprecatedISANUpid | segmentation_upid_type = 0x05 (deprecated), ISAN of 8 bytes
+//ISAN is represented as hex groups without check characters, e.g. 0000-0000-3A8D-0000
+type DeprecatedISANUpid struct {
+	ISAN string `json:"isan"`
+}
+
+func (upid *DeprecatedISANUpid) UpidType() SegmentationUpidType { return 0x05 }
+func (upid *DeprecatedISANUpid) String() string                 { return upid.ISAN }
+
+func (upid *DeprecatedISANUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("ISAN", input, 8); err != nil {
+		return 0, err
+	}
+	upid.ISAN = groupHex(input, 4, "-")
+	return 64, nil
+}
+
+func (upid *DeprecatedISANUpid) EncodeToRawBytes() ([]byte, error) {
+	return decodeGroupedHex("ISAN", upid.ISAN, "-", 8)
+}
+
+//ISANUpid | segmentation_upid_type = 0x06, V-ISAN of 12 bytes
+//ISAN is represented as hex groups without check characters, e.g. 0000-0001-2C52-0000-0000-0000
+type ISANUpid struct {
+	ISAN string `json:"isan"`
+}
+
+func (upid *ISANUpid) UpidType() SegmentationUpidType { return 0x06 }
+func (upid *ISANUpid) String() string                 { return upid.ISAN }
+
+func (upid *ISANUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("V-ISAN", input, 12); err != nil {
+		return 0, err
+	}
+	upid.ISAN = groupHex(input, 4, "-")
+	return 96, nil
+}
+
+func (upid *ISANUpid) EncodeToRawBytes() ([]byte, error) {
+	return decodeGroupedHex("V-ISAN", upid.ISAN, "-", 12)
+}
+
+//TIDUpid | segmentation_upid_type = 0x07, Tribune Media Systems Program identifier of 12 characters
+type TIDUpid struct {
+	TID string `json:"tid"`
+}
+
+func (upid *TIDUpid) UpidType() SegmentationUpidType { return 0x07 }
+func (upid *TIDUpid) String() string                 { return upid.TID }
+
+func (upid *TIDUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("TID", input, 12); err != nil {
+		return 0, err
+	}
+	upid.TID = string(input)
+	return 96, nil
+}
+
+func (upid *TIDUpid) EncodeToRawBytes() ([]byte, error) {
+	return encodeFixedLengthString("TID", upid.TID, 12)
+}
+
+//TIUpid | segmentation_upid_type = 0x08, Turner identifier(AiringID) of 64 bits
+type TIUpid struct {
+	TI uint64 `json:"ti"`
+}
+
+func (upid *TIUpid) UpidType() SegmentationUpidType { return 0x08 }
+func (upid *TIUpid) String() string                 { return "0x" + strconv.FormatUint(upid.TI, 16) }
+
+func (upid *TIUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("TI", input, 8); err != nil {
+		return 0, err
+	}
+	upid.TI = 0
+	for _, b := range input {
+		upid.TI = upid.TI<<8 | uint64(b)
+	}
+	return 64, nil
+}
+
+func (upid *TIUpid) EncodeToRawBytes() ([]byte, error) {
+	w := &BitWriter{}
+	w.WriteBits(upid.TI, 64)
+	return w.Bytes()
+}
+
+//ADIUpid | segmentation_upid_type = 0x09, CableLabs metadata identifier of variable length, e.g. PROVIDER/ASSET_ID
+type ADIUpid struct {
+	ADI string `json:"adi"`
+}
+
+func (upid *ADIUpid) UpidType() SegmentationUpidType { return 0x09 }
+func (upid *ADIUpid) String() string                 { return upid.ADI }
+
+func (upid *ADIUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	upid.ADI = string(input)
+	return len(input) * 8, nil
+}
+
+func (upid *ADIUpid) EncodeToRawBytes() ([]byte, error) {
+	return []byte(upid.ADI), nil
+}
+
+//EIDRUpid | segmentation_upid_type = 0x0A, EIDR in compact binary form of 12 bytes
+//EIDR is represented in its canonical form with the check character, e.g. 10.5240/7791-8534-2C23-9030-8610-5
+type EIDRUpid struct {
+	EIDR string `json:"eidr"`
+}
+
+func (upid *EIDRUpid) UpidType() SegmentationUpidType { return 0x0A }
+func (upid *EIDRUpid) String() string                 { return upid.EIDR }
+
+func (upid *EIDRUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("EIDR", input, 12); err != nil {
+		return 0, err
+	}
+
+	prefix := int(input[0])<<8 | int(input[1])
+	suffix := strings.ToUpper(hex.EncodeToString(input[2:]))
+	upid.EIDR = "10." + strconv.Itoa(prefix) + "/" + groupHex(input[2:], 2, "-") + "-" + string(eidrCheckCharacter(suffix))
+	return 96, nil
+}
+
+func (upid *EIDRUpid) EncodeToRawBytes() ([]byte, error) {
+	slashPos := strings.Index(upid.EIDR, "/")
+	if !strings.HasPrefix(upid.EIDR, "10.") || slashPos < 0 {
+		return nil, errors.New("Encode Error: Invalid EIDR: " + upid.EIDR)
+	}
+	prefix, err := strconv.ParseUint(upid.EIDR[3:slashPos], 10, 16)
+	if err != nil {
+		return nil, errors.New("Encode Error: Invalid EIDR Prefix: " + upid.EIDR)
+	}
+
+	suffix := strings.Replace(upid.EIDR[slashPos+1:], "-", "", -1)
+	if len(suffix) == 21 {
+		suffix = suffix[:20] //drop the check character
+	}
+	suffixBytes, err := hex.DecodeString(suffix)
+	if err != nil || len(suffixBytes) != 10 {
+		return nil, errors.New("Encode Error: Invalid EIDR Suffix: " + upid.EIDR)
+	}
+
+	return append([]byte{byte(prefix >> 8), byte(prefix)}, suffixBytes...), nil
+}
+
+//eidrCheckCharacter calculates the ISO 7064 Mod 37,36 check character of the EIDR suffix
+func eidrCheckCharacter(suffix string) byte {
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	const m = 36
+
+	p := m
+	for _, c := range suffix {
+		s := (p + strings.IndexRune(alphabet, c)) % m
+		if s == 0 {
+			s = m
+		}
+		p = (s * 2) % (m + 1)
+	}
+	return alphabet[(m+1-p)%m]
+}
+
+//ATSCContentIdentifierUpid | segmentation_upid_type = 0x0B, content_identifier of ATSC A/57B
+type ATSCContentIdentifierUpid struct {
+	TSID           uint16 `json:"tsid"`
+	EndOfDay       uint8  `json:"end_of_day"` //5 bits
+	UniqueFor      uint16 `json:"unique_for"` //9 bits
+	ContentIDInHex string `json:"content_id_in_hex"`
+}
+
+func (upid *ATSCContentIdentifierUpid) UpidType() SegmentationUpidType { return 0x0B }
+
+func (upid *ATSCContentIdentifierUpid) String() string {
+	return strconv.Itoa(int(upid.TSID)) + "/" + upid.ContentIDInHex
+}
+
+func (upid *ATSCContentIdentifierUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if len(input) < 4 {
+		return 0, errors.New("Parse Error: ATSC Content Identifier requires at least 4 bytes, got " + strconv.Itoa(len(input)))
+	}
+
+	upid.TSID = uint16(input[0])<<8 | uint16(input[1])
+	//reserved 2 bits
+	upid.EndOfDay = (input[2] >> 1) & 0x1f
+	upid.UniqueFor = uint16(input[2]&0x01)<<8 | uint16(input[3])
+	upid.ContentIDInHex = hex.EncodeToString(input[4:])
+	return len(input) * 8, nil
+}
+
+func (upid *ATSCContentIdentifierUpid) EncodeToRawBytes() ([]byte, error) {
+	w := &BitWriter{}
+
+	w.WriteBits(uint64(upid.TSID), 16)
+	w.WriteReserved(2)
+	w.WriteBits(uint64(upid.EndOfDay), 5)
+	w.WriteBits(uint64(upid.UniqueFor), 9)
+	if err := w.WriteHexString(upid.ContentIDInHex); err != nil {
+		return nil, err
+	}
+
+	return w.Bytes()
+}
+
+//MPUUpid | segmentation_upid_type = 0x0C, Managed Private UPID
+type MPUUpid struct {
+	FormatIdentifier uint32 `json:"format_identifier"`
+	PrivateDataInHex string `json:"private_data_in_hex"`
+}
+
+func (upid *MPUUpid) UpidType() SegmentationUpidType { return 0x0C }
+
+func (upid *MPUUpid) String() string {
+	return FourCC(upid.FormatIdentifier) + ":" + upid.PrivateDataInHex
+}
+
+func (upid *MPUUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if len(input) < 4 {
+		return 0, errors.New("Parse Error: MPU requires at least 4 bytes for format_identifier, got " + strconv.Itoa(len(input)))
+	}
+
+	upid.FormatIdentifier = uint32(input[0])<<24 | uint32(input[1])<<16 | uint32(input[2])<<8 | uint32(input[3])
+	upid.PrivateDataInHex = hex.EncodeToString(input[4:])
+	return len(input) * 8, nil
+}
+
+func (upid *MPUUpid) EncodeToRawBytes() ([]byte, error) {
+	w := &BitWriter{}
+
+	w.WriteBits(uint64(upid.FormatIdentifier), 32)
+	if err := w.WriteHexString(upid.PrivateDataInHex); err != nil {
+		return nil, err
+	}
+
+	return w.Bytes()
+}
+
+//MIDUpid | segmentation_upid_type = 0x0D, Multiple UPID which carries an ordered list of UPIDs
+type MIDUpid struct {
+	Upids []MIDUpidEntry `json:"upids"`
+}
+
+//MIDUpidEntry is a UPID carried in MID, it has the same layout as the UPID fields of segmentation descriptor
+type MIDUpidEntry struct {
+	SegmentationUpidType   SegmentationUpidType `json:"segmentation_upid_type"`
+	SegmentationUpidLength uint8                `json:"segmentation_upid_length"`
+	SegmentationUpidInHex  string               `json:"segmentation_upid_in_hex"`
+	SegmentationUpid       *SegmentationUpid    `json:"segmentation_upid,omitempty"` //typed value of segmentation_upid_in_hex
+}
+
+func (upid *MIDUpid) UpidType() SegmentationUpidType { return 0x0D }
+
+func (upid *MIDUpid) String() string {
+	var values []string
+	for _, entry := range upid.Upids {
+		if entry.SegmentationUpid != nil && entry.SegmentationUpid.Upid != nil {
+			values = append(values, entry.SegmentationUpid.String())
+		} else {
+			values = append(values, entry.SegmentationUpidInHex)
+		}
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+//DecodeFromRawBytes parses input []byte to MIDUpid object, every nested UPID is decoded to its typed value
+func (upid *MIDUpid) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	upid.Upids = []MIDUpidEntry{}
+
+	pos := 0
+	for i := 0; pos < len(input); i++ {
+		if len(input)-pos < 2 {
+			return 0, errors.New("Parse Error: MID upids[" + strconv.Itoa(i) + "] requires 2 bytes for segmentation_upid_type and segmentation_upid_length, got " + strconv.Itoa(len(input)-pos))
+		}
+
+		entry := MIDUpidEntry{}
+		entry.SegmentationUpidType = SegmentationUpidType(input[pos])
+		entry.SegmentationUpidLength = input[pos+1]
+		pos += 2
+
+		if int(entry.SegmentationUpidLength) > len(input)-pos {
+			return 0, errors.New("Parse Error: MID upids[" + strconv.Itoa(i) + "].segmentation_upid_length(" + strconv.Itoa(int(entry.SegmentationUpidLength)) + ") is more than the bytes left(" + strconv.Itoa(len(input)-pos) + ")")
+		}
+		upidBytes := input[pos : pos+int(entry.SegmentationUpidLength)]
+		pos += int(entry.SegmentationUpidLength)
+
+		entry.SegmentationUpidInHex = hex.EncodeToString(upidBytes)
+		typedUpid, err := DecodeUpid(entry.SegmentationUpidType, upidBytes)
+		if err != nil {
+			return 0, errors.New("Parse Error: MID upids[" + strconv.Itoa(i) + "]: " + err.Error())
+		}
+		if typedUpid != nil {
+			entry.SegmentationUpid = &SegmentationUpid{Upid: typedUpid}
+		}
+
+		upid.Upids = append(upid.Upids, entry)
+	}
+
+	return pos * 8, nil
+}
+
+//EncodeToRawBytes serializes MIDUpid object to []byte, segmentation_upid_length of every nested UPID is recalculated
+//As in SegmentationDescriptor, the typed value of a nested UPID is only used when its segmentation_upid_in_hex is empty
+func (upid *MIDUpid) EncodeToRawBytes() (output []byte, err error) {
+	var upidBytes []byte
+
+	for i := range upid.Upids {
+		entry := &upid.Upids[i]
+		if entry.SegmentationUpidInHex != "" {
+			if upidBytes, err = hex.DecodeString(entry.SegmentationUpidInHex); err != nil {
+				return nil, err
+			}
+		} else if entry.SegmentationUpid != nil {
+			if err = entry.SegmentationUpid.resolve(entry.SegmentationUpidType); err != nil {
+				return nil, errors.New("Encode Error: MID upids[" + strconv.Itoa(i) + "]: " + err.Error())
+			}
+			if entry.SegmentationUpid.UpidType() != entry.SegmentationUpidType {
+				return nil, errors.New("Encode Error: MID upids[" + strconv.Itoa(i) + "] has segmentation_upid_type " + strconv.Itoa(int(entry.SegmentationUpidType)) + " but a typed value of type " + strconv.Itoa(int(entry.SegmentationUpid.UpidType())))
+			}
+			if upidBytes, err = entry.SegmentationUpid.EncodeToRawBytes(); err != nil {
+				return nil, errors.New("Encode Error: MID upids[" + strconv.Itoa(i) + "]: " + err.Error())
+			}
+		} else {
+			upidBytes = []byte{}
+		}
+
+		if len(upidBytes) > 0xff {
+			return nil, errors.New("Encode Error: MID upids[" + strconv.Itoa(i) + "] is longer than 255 bytes")
+		}
+		entry.SegmentationUpidLength = uint8(len(upidBytes))
+
+		output = append(output, byte(entry.SegmentationUpidType), entry.SegmentationUpidLength)
+		output = append(output, upidBytes...)
+	}
+
+	if len(output) > 0xff {
+		return nil, errors.New("Encode Error: MID is longer than 255 bytes")
+	}
+	return output, nil
+}
+
+//ADSInformationUpid | segmentation_upid_type = 0x0E, advertising information of variable length
+type ADSInformationUpid struct {
+	ADSInformation string `json:"ads_information"`
+}
+
+func (upid *ADSInformationUpid) UpidType() SegmentationUpidType { return 0x0E }
+func (upid *ADSInformationUpid) String() string                 { return upid.ADSInformation }
+
+func (upid *ADSInformationUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	upid.ADSInformation = string(input)
+	return len(input) * 8, nil
+}
+
+func (upid *ADSInformationUpid) EncodeToRawBytes() ([]byte, error) {
+	return []byte(upid.ADSInformation), nil
+}
+
+//URIUpid | segmentation_upid_type = 0x0F, URI of variable length
+type URIUpid struct {
+	URI string `json:"uri"`
+}
+
+func (upid *URIUpid) UpidType() SegmentationUpidType { return 0x0F }
+func (upid *URIUpid) String() string                 { return upid.URI }
+
+func (upid *URIUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	upid.URI = string(input)
+	return len(input) * 8, nil
+}
+
+func (upid *URIUpid) EncodeToRawBytes() ([]byte, error) {
+	return []byte(upid.URI), nil
+}
+
+//UUIDUpid | segmentation_upid_type = 0x10, UUID of 16 bytes in its canonical form, e.g. 123e4567-e89b-12d3-a456-426614174000
+type UUIDUpid struct {
+	UUID string `json:"uuid"`
+}
+
+func (upid *UUIDUpid) UpidType() SegmentationUpidType { return 0x10 }
+func (upid *UUIDUpid) String() string                 { return upid.UUID }
+
+func (upid *UUIDUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	if err := checkUpidLength("UUID", input, 16); err != nil {
+		return 0, err
+	}
+	hexStr := hex.EncodeToString(input)
+	upid.UUID = hexStr[0:8] + "-" + hexStr[8:12] + "-" + hexStr[12:16] + "-" + hexStr[16:20] + "-" + hexStr[20:32]
+	return 128, nil
+}
+
+func (upid *UUIDUpid) EncodeToRawBytes() ([]byte, error) {
+	return decodeGroupedHex("UUID", upid.UUID, "-", 16)
+}
+
+//SCRUpid | segmentation_upid_type = 0x11, Subscriber Company Reporting parameters of variable length
+type SCRUpid struct {
+	SCR string `json:"scr"`
+}
+
+func (upid *SCRUpid) UpidType() SegmentationUpidType { return 0x11 }
+func (upid *SCRUpid) String() string                 { return upid.SCR }
+
+func (upid *SCRUpid) DecodeFromRawBytes(input []byte) (int, error) {
+	upid.SCR = string(input)
+	return len(input) * 8, nil
+}
+
+func (upid *SCRUpid) EncodeToRawBytes() ([]byte, error) {
+	return []byte(upid.SCR), nil
+}
+
+//FourCC returns identifier as 4 characters, e.g. 0x43554549 => CUEI
+func FourCC(identifier uint32) string {
+	return string([]byte{byte(identifier >> 24), byte(identifier >> 16), byte(identifier >> 8), byte(identifier)})
+}
+
+//groupHex formats input as upper case hex, with a separator after every bytesPerGroup bytes
+func groupHex(input []byte, bytesPerGroup int, separator string) string {
+	var groups []string
+	for i := 0; i < len(input); i += bytesPerGroup {
+		end := i + bytesPerGroup
+		if end > len(input) {
+			end = len(input)
+		}
+		groups = append(groups, strings.ToUpper(hex.EncodeToString(input[i:end])))
+	}
+	return strings.Join(groups, separator)
+}
+
+func decodeGroupedHex(name string, value string, separator string, expected int) ([]byte, error) {
+	output, err := hex.DecodeString(strings.Replace(value, separator, "", -1))
+	if err == nil && len(output) != expected {
+		err = errors.New("Encode Error: " + name + " requires " + strconv.Itoa(expected) + " bytes: " + value)
+	}
+	return output, err
+}
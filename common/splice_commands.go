@@ -1,7 +1,8 @@
 package common
 
 import (
-	bits "github.com/chanyk-joseph/gobits"
+	"errors"
+	"strconv"
 )
 
 //SpliceNull | splice_command_type = 0x00
@@ -93,264 +94,517 @@ type InsertComponent struct {
 
 //DecodeFromRawBytes parses input []byte to PrivateCommand object
 func (privateCommand *PrivateCommand) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	privateCommand.Identifier, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
+	r := NewBitReader(input)
+	privateCommand.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-	bytesLeft := len(input) - 4
-	_, privateCommand.PrivateByteInHex, err = bits.HexString(input, numOfParsedBits, bytesLeft*8)
-	numOfParsedBits += (bytesLeft * 8)
+//DecodeFromBitReader parses PrivateCommand object from r, the private bytes take the rest of r
+func (privateCommand *PrivateCommand) DecodeFromBitReader(r *BitReader) {
+	privateCommand.Identifier = r.Uint32("identifier")
 
-	return numOfParsedBits, err
+	privateByteInHex := r.HexString("private_byte", r.Left()/8)
+	privateCommand.PrivateByteInHex = &privateByteInHex
 }
 
 //DecodeFromRawBytes parses input []byte to SpliceInsert object
 func (spliceInsert *SpliceInsert) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
-	var tmpUsedBits int
-
-	spliceInsert.SpliceEventID, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
-
-	spliceInsert.SpliceEventCancelIndicator, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
+	r := NewBitReader(input)
+	spliceInsert.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-	numOfParsedBits += 7 //reserved 7 bits
+//DecodeFromBitReader parses SpliceInsert object from r
+func (spliceInsert *SpliceInsert) DecodeFromBitReader(r *BitReader) {
+	spliceInsert.SpliceEventID = r.Uint32("splice_event_id")
+	spliceInsert.SpliceEventCancelIndicator = r.Bool("splice_event_cancel_indicator")
+	r.Reserved(7)
 
 	if !spliceInsert.SpliceEventCancelIndicator {
-		_, spliceInsert.OutOfNetworkIndicator, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		outOfNetworkIndicator := r.Bool("out_of_network_indicator")
+		spliceInsert.OutOfNetworkIndicator = &outOfNetworkIndicator
 
-		_, spliceInsert.ProgramSpliceFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		programSpliceFlag := r.Bool("program_splice_flag")
+		spliceInsert.ProgramSpliceFlag = &programSpliceFlag
 
-		_, spliceInsert.DurationFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		durationFlag := r.Bool("duration_flag")
+		spliceInsert.DurationFlag = &durationFlag
 
-		_, spliceInsert.SpliceImmediateFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		spliceImmediateFlag := r.Bool("splice_immediate_flag")
+		spliceInsert.SpliceImmediateFlag = &spliceImmediateFlag
 
-		numOfParsedBits += 4 //reserved 4 bits
+		r.Reserved(4)
 
-		if *spliceInsert.ProgramSpliceFlag && !*spliceInsert.SpliceImmediateFlag {
+		if programSpliceFlag && !spliceImmediateFlag {
 			spliceInsert.SpliceTime = &SpliceTime{}
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-			tmpUsedBits, err = spliceInsert.SpliceTime.DecodeFromRawBytes(tmpBytes)
-			numOfParsedBits += tmpUsedBits
+			sub := r.Sub("splice_time", -1)
+			spliceInsert.SpliceTime.DecodeFromBitReader(sub)
+			r.Merge(sub)
 		}
-		if !(*spliceInsert.ProgramSpliceFlag) {
-			_, spliceInsert.ComponentCount, err = bits.Uint8(input, numOfParsedBits)
-			numOfParsedBits += 8
+		if !programSpliceFlag {
+			componentCount := r.Uint8("component_count")
+			spliceInsert.ComponentCount = &componentCount
 
 			var insertComponents []InsertComponent
-			for i := 0; i < int(*spliceInsert.ComponentCount); i++ {
+			for i := 0; i < int(componentCount) && r.Err() == nil; i++ {
 				comp := &InsertComponent{}
-				tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-				tmpUsedBits, err = comp.DecodeFromRawBytes(tmpBytes, *spliceInsert.SpliceImmediateFlag)
-				numOfParsedBits += tmpUsedBits
+				sub := r.Sub("insert_components["+strconv.Itoa(i)+"]", -1)
+				comp.DecodeFromBitReader(sub, spliceImmediateFlag)
+				r.Merge(sub)
 
 				insertComponents = append(insertComponents, *comp)
 			}
 			spliceInsert.InsertComponents = &insertComponents
 		}
 
-		if *spliceInsert.DurationFlag {
-			b := &BreakDuration{}
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-			tmpUsedBits, err = b.DecodeFromRawBytes(tmpBytes)
-			spliceInsert.BreakDuration = b
-			numOfParsedBits += tmpUsedBits
+		if durationFlag {
+			spliceInsert.BreakDuration = &BreakDuration{}
+			sub := r.Sub("break_duration", -1)
+			spliceInsert.BreakDuration.DecodeFromBitReader(sub)
+			r.Merge(sub)
 		}
 
-		_, spliceInsert.UniqueProgramID, err = bits.Uint16(input, numOfParsedBits)
-		numOfParsedBits += 16
-
-		_, spliceInsert.AvailNum, err = bits.Byte(input, numOfParsedBits)
-		numOfParsedBits += 8
+		uniqueProgramID := r.Uint16("unique_program_id")
+		spliceInsert.UniqueProgramID = &uniqueProgramID
 
-		_, spliceInsert.AvailsExpected, err = bits.Byte(input, numOfParsedBits)
-		numOfParsedBits += 8
-	}
+		availNum := r.Uint8("avail_num")
+		spliceInsert.AvailNum = &availNum
 
-	if err != nil {
-		return 0, err
+		availsExpected := r.Uint8("avails_expected")
+		spliceInsert.AvailsExpected = &availsExpected
 	}
-	return numOfParsedBits, err
 }
 
 //DecodeFromRawBytes parses input []byte to ScheduleEvent object
 func (scheduleEvent *ScheduleEvent) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
-	var tmpUsedBits int
-
-	scheduleEvent.SpliceEventID, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
-
-	scheduleEvent.SpliceEventCancelIndicator, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
+	r := NewBitReader(input)
+	scheduleEvent.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-	numOfParsedBits += 7 //reserved 7 bits
+//DecodeFromBitReader parses ScheduleEvent object from r
+func (scheduleEvent *ScheduleEvent) DecodeFromBitReader(r *BitReader) {
+	scheduleEvent.SpliceEventID = r.Uint32("splice_event_id")
+	scheduleEvent.SpliceEventCancelIndicator = r.Bool("splice_event_cancel_indicator")
+	r.Reserved(7)
 
 	if !scheduleEvent.SpliceEventCancelIndicator {
-		_, scheduleEvent.OutOfNetworkIndicator, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		outOfNetworkIndicator := r.Bool("out_of_network_indicator")
+		scheduleEvent.OutOfNetworkIndicator = &outOfNetworkIndicator
 
-		_, scheduleEvent.ProgramSpliceFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		programSpliceFlag := r.Bool("program_splice_flag")
+		scheduleEvent.ProgramSpliceFlag = &programSpliceFlag
 
-		_, scheduleEvent.DurationFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		durationFlag := r.Bool("duration_flag")
+		scheduleEvent.DurationFlag = &durationFlag
 
-		numOfParsedBits += 4 //reserved 5 bits
+		r.Reserved(5)
 
-		if *scheduleEvent.ProgramSpliceFlag {
-			_, scheduleEvent.UTCSpliceTime, err = bits.Uint32(input, numOfParsedBits)
-			numOfParsedBits += 32
+		if programSpliceFlag {
+			utcSpliceTime := r.Uint32("utc_splice_time")
+			scheduleEvent.UTCSpliceTime = &utcSpliceTime
 		}
-		if !(*scheduleEvent.ProgramSpliceFlag) {
-			_, scheduleEvent.ComponentCount, err = bits.Uint8(input, numOfParsedBits)
-			numOfParsedBits += 8
+		if !programSpliceFlag {
+			componentCount := r.Uint8("component_count")
+			scheduleEvent.ComponentCount = &componentCount
 
 			var scheduleComponents []ScheduleComponent
-			for i := 0; i < int(*scheduleEvent.ComponentCount); i++ {
+			for i := 0; i < int(componentCount) && r.Err() == nil; i++ {
 				comp := &ScheduleComponent{}
-				tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 40)
-				tmpUsedBits, err = comp.DecodeFromRawBytes(tmpBytes)
-				numOfParsedBits += tmpUsedBits
+				sub := r.Sub("schedule_components["+strconv.Itoa(i)+"]", 40)
+				comp.DecodeFromBitReader(sub)
+				r.Merge(sub)
 
 				scheduleComponents = append(scheduleComponents, *comp)
 			}
 			scheduleEvent.ScheduleComponents = &scheduleComponents
 		}
 
-		if *scheduleEvent.DurationFlag {
-			b := &BreakDuration{}
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-			tmpUsedBits, err = b.DecodeFromRawBytes(tmpBytes)
-			scheduleEvent.BreakDuration = b
-			numOfParsedBits += tmpUsedBits
+		if durationFlag {
+			scheduleEvent.BreakDuration = &BreakDuration{}
+			sub := r.Sub("break_duration", -1)
+			scheduleEvent.BreakDuration.DecodeFromBitReader(sub)
+			r.Merge(sub)
 		}
 
-		_, scheduleEvent.UniqueProgramID, err = bits.Uint16(input, numOfParsedBits)
-		numOfParsedBits += 16
+		uniqueProgramID := r.Uint16("unique_program_id")
+		scheduleEvent.UniqueProgramID = &uniqueProgramID
 
-		_, scheduleEvent.AvailNum, err = bits.Byte(input, numOfParsedBits)
-		numOfParsedBits += 8
+		availNum := r.Uint8("avail_num")
+		scheduleEvent.AvailNum = &availNum
 
-		_, scheduleEvent.AvailsExpected, err = bits.Byte(input, numOfParsedBits)
-		numOfParsedBits += 8
+		availsExpected := r.Uint8("avails_expected")
+		scheduleEvent.AvailsExpected = &availsExpected
 	}
-
-	return numOfParsedBits, nil
 }
 
 //DecodeFromRawBytes parses input []byte to SpliceSchedule object
 func (spliceSchedule *SpliceSchedule) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
-	var tmpUsedBits int
+	r := NewBitReader(input)
+	spliceSchedule.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-	spliceSchedule.SpliceCount, _, err = bits.Uint8(input, numOfParsedBits)
-	numOfParsedBits += 8
+//DecodeFromBitReader parses SpliceSchedule object from r
+func (spliceSchedule *SpliceSchedule) DecodeFromBitReader(r *BitReader) {
+	spliceSchedule.SpliceCount = r.Uint8("splice_count")
 
 	tmpEvents := []ScheduleEvent{}
-	for i := 0; i < int(spliceSchedule.SpliceCount); i++ {
+	for i := 0; i < int(spliceSchedule.SpliceCount) && r.Err() == nil; i++ {
 		scheduleEvent := &ScheduleEvent{}
-		tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-		tmpUsedBits, err = scheduleEvent.DecodeFromRawBytes(tmpBytes)
+		sub := r.Sub("schedule_events["+strconv.Itoa(i)+"]", -1)
+		scheduleEvent.DecodeFromBitReader(sub)
+		r.Merge(sub)
 
 		tmpEvents = append(tmpEvents, *scheduleEvent)
-		numOfParsedBits += tmpUsedBits
 	}
 	spliceSchedule.ScheduleEvents = &tmpEvents
-
-	if err != nil {
-		return 0, err
-	}
-	return numOfParsedBits, err
 }
 
 //DecodeFromRawBytes parses input []byte to InsertComponent object
 func (insertComponent *InsertComponent) DecodeFromRawBytes(input []byte, spliceImmediateFlag bool) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
+	r := NewBitReader(input)
+	insertComponent.DecodeFromBitReader(r, spliceImmediateFlag)
+	return r.Result()
+}
 
-	insertComponent.ComponentTag, _, err = bits.Byte(input, numOfParsedBits)
-	numOfParsedBits += 8
+//DecodeFromBitReader parses InsertComponent object from r
+func (insertComponent *InsertComponent) DecodeFromBitReader(r *BitReader, spliceImmediateFlag bool) {
+	insertComponent.ComponentTag = r.Uint8("component_tag")
 
 	if !spliceImmediateFlag {
 		insertComponent.SpliceTime = &SpliceTime{}
+		sub := r.Sub("splice_time", -1)
+		insertComponent.SpliceTime.DecodeFromBitReader(sub)
+		r.Merge(sub)
+	}
+}
+
+//DecodeFromRawBytes parses input []byte to ScheduleComponent object
+func (scheduleComponent *ScheduleComponent) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := NewBitReader(input)
+	scheduleComponent.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses ScheduleComponent object from r
+func (scheduleComponent *ScheduleComponent) DecodeFromBitReader(r *BitReader) {
+	scheduleComponent.ComponentTag = r.Uint8("component_tag")
+	scheduleComponent.UTCSpliceTime = r.Uint32("utc_splice_time")
+}
+
+//DecodeFromRawBytes parses input []byte to BreakDuration object
+func (breakDuration *BreakDuration) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := NewBitReader(input)
+	breakDuration.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-		bitsUsed := 0
-		tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 0)
-		bitsUsed, err = insertComponent.SpliceTime.DecodeFromRawBytes(tmpBytes)
-		numOfParsedBits += bitsUsed
+//DecodeFromBitReader parses BreakDuration object from r
+func (breakDuration *BreakDuration) DecodeFromBitReader(r *BitReader) {
+	breakDuration.AutoReturn = r.Bool("auto_return")
+	r.Reserved(6)
+	breakDuration.Duration = r.Bits("duration", 33)
+}
+
+//DecodeFromRawBytes parses input []byte to SpliceTime object
+func (spliceTime *SpliceTime) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := NewBitReader(input)
+	spliceTime.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses SpliceTime object from r
+func (spliceTime *SpliceTime) DecodeFromBitReader(r *BitReader) {
+	spliceTime.TimeSpecifiedFlag = r.Bool("time_specified_flag")
+
+	if spliceTime.TimeSpecifiedFlag {
+		r.Reserved(6)
+		ptsTime := r.Bits("pts_time", 33)
+		spliceTime.PTSTime = &ptsTime
+	} else {
+		r.Reserved(7)
 	}
+}
+
+//DecodeFromRawBytes parses input []byte to TimeSignal object
+func (timeSignal *TimeSignal) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := NewBitReader(input)
+	timeSignal.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses TimeSignal object from r
+func (timeSignal *TimeSignal) DecodeFromBitReader(r *BitReader) {
+	timeSignal.SpliceTime = &SpliceTime{}
+	sub := r.Sub("splice_time", -1)
+	timeSignal.SpliceTime.DecodeFromBitReader(sub)
+	r.Merge(sub)
+}
+
+//EncodeToRawBytes serializes PrivateCommand object to []byte
+func (privateCommand *PrivateCommand) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
 
-	if err != nil {
-		return 0, err
+	w.WriteBits(uint64(privateCommand.Identifier), 32)
+	if privateCommand.PrivateByteInHex != nil {
+		if err = w.WriteHexString(*privateCommand.PrivateByteInHex); err != nil {
+			return nil, err
+		}
 	}
-	return numOfParsedBits, err
+
+	return w.Bytes()
 }
 
-//DecodeFromRawBytes parses input []byte to ScheduleComponent object
-func (scheduleComponent *ScheduleComponent) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	scheduleComponent.ComponentTag, _, err = bits.Byte(input, numOfParsedBits)
-	numOfParsedBits += 8
+//EncodeToRawBytes serializes SpliceInsert object to []byte
+func (spliceInsert *SpliceInsert) EncodeToRawBytes() (output []byte, err error) {
+	var tmpBytes []byte
+	w := &BitWriter{}
+
+	w.WriteBits(uint64(spliceInsert.SpliceEventID), 32)
+	w.WriteBool(spliceInsert.SpliceEventCancelIndicator)
+	w.WriteReserved(7)
+
+	if !spliceInsert.SpliceEventCancelIndicator {
+		if spliceInsert.OutOfNetworkIndicator == nil {
+			return nil, MissingFieldError("out_of_network_indicator")
+		}
+		if spliceInsert.ProgramSpliceFlag == nil {
+			return nil, MissingFieldError("program_splice_flag")
+		}
+		if spliceInsert.DurationFlag == nil {
+			return nil, MissingFieldError("duration_flag")
+		}
+		if spliceInsert.SpliceImmediateFlag == nil {
+			return nil, MissingFieldError("splice_immediate_flag")
+		}
+		w.WriteBool(*spliceInsert.OutOfNetworkIndicator)
+		w.WriteBool(*spliceInsert.ProgramSpliceFlag)
+		w.WriteBool(*spliceInsert.DurationFlag)
+		w.WriteBool(*spliceInsert.SpliceImmediateFlag)
+		w.WriteReserved(4)
+
+		if *spliceInsert.ProgramSpliceFlag && !*spliceInsert.SpliceImmediateFlag {
+			if spliceInsert.SpliceTime == nil {
+				return nil, MissingFieldError("splice_time")
+			}
+			if tmpBytes, err = spliceInsert.SpliceTime.EncodeToRawBytes(); err != nil {
+				return nil, err
+			}
+			w.WriteBytes(tmpBytes)
+		}
+		if !(*spliceInsert.ProgramSpliceFlag) {
+			var insertComponents []InsertComponent
+			if spliceInsert.InsertComponents != nil {
+				insertComponents = *spliceInsert.InsertComponents
+			}
+			if len(insertComponents) > 0xff {
+				return nil, errors.New("Encode Error: splice_insert has more than 255 components")
+			}
+			componentCount := uint8(len(insertComponents))
+			spliceInsert.ComponentCount = &componentCount
+			w.WriteBits(uint64(componentCount), 8)
+
+			for i := range insertComponents {
+				if tmpBytes, err = insertComponents[i].EncodeToRawBytes(*spliceInsert.SpliceImmediateFlag); err != nil {
+					return nil, err
+				}
+				w.WriteBytes(tmpBytes)
+			}
+		}
 
-	scheduleComponent.UTCSpliceTime, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
+		if *spliceInsert.DurationFlag {
+			if spliceInsert.BreakDuration == nil {
+				return nil, MissingFieldError("break_duration")
+			}
+			if tmpBytes, err = spliceInsert.BreakDuration.EncodeToRawBytes(); err != nil {
+				return nil, err
+			}
+			w.WriteBytes(tmpBytes)
+		}
 
-	if err != nil {
-		return 0, err
+		if spliceInsert.UniqueProgramID == nil {
+			return nil, MissingFieldError("unique_program_id")
+		}
+		if spliceInsert.AvailNum == nil {
+			return nil, MissingFieldError("avail_num")
+		}
+		if spliceInsert.AvailsExpected == nil {
+			return nil, MissingFieldError("avails_expected")
+		}
+		w.WriteBits(uint64(*spliceInsert.UniqueProgramID), 16)
+		w.WriteBits(uint64(*spliceInsert.AvailNum), 8)
+		w.WriteBits(uint64(*spliceInsert.AvailsExpected), 8)
 	}
-	return numOfParsedBits, nil
+
+	return w.Bytes()
 }
 
-//DecodeFromRawBytes parses input []byte to BreakDuration object
-func (breakDuration *BreakDuration) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+//EncodeToRawBytes serializes ScheduleEvent object to []byte
+func (scheduleEvent *ScheduleEvent) EncodeToRawBytes() (output []byte, err error) {
 	var tmpBytes []byte
+	w := &BitWriter{}
+
+	w.WriteBits(uint64(scheduleEvent.SpliceEventID), 32)
+	w.WriteBool(scheduleEvent.SpliceEventCancelIndicator)
+	w.WriteReserved(7)
+
+	if !scheduleEvent.SpliceEventCancelIndicator {
+		if scheduleEvent.OutOfNetworkIndicator == nil {
+			return nil, MissingFieldError("out_of_network_indicator")
+		}
+		if scheduleEvent.ProgramSpliceFlag == nil {
+			return nil, MissingFieldError("program_splice_flag")
+		}
+		if scheduleEvent.DurationFlag == nil {
+			return nil, MissingFieldError("duration_flag")
+		}
+		w.WriteBool(*scheduleEvent.OutOfNetworkIndicator)
+		w.WriteBool(*scheduleEvent.ProgramSpliceFlag)
+		w.WriteBool(*scheduleEvent.DurationFlag)
+		w.WriteReserved(5)
+
+		if *scheduleEvent.ProgramSpliceFlag {
+			if scheduleEvent.UTCSpliceTime == nil {
+				return nil, MissingFieldError("utc_splice_time")
+			}
+			w.WriteBits(uint64(*scheduleEvent.UTCSpliceTime), 32)
+		}
+		if !(*scheduleEvent.ProgramSpliceFlag) {
+			var scheduleComponents []ScheduleComponent
+			if scheduleEvent.ScheduleComponents != nil {
+				scheduleComponents = *scheduleEvent.ScheduleComponents
+			}
+			if len(scheduleComponents) > 0xff {
+				return nil, errors.New("Encode Error: splice_schedule event has more than 255 components")
+			}
+			componentCount := uint8(len(scheduleComponents))
+			scheduleEvent.ComponentCount = &componentCount
+			w.WriteBits(uint64(componentCount), 8)
+
+			for i := range scheduleComponents {
+				if tmpBytes, err = scheduleComponents[i].EncodeToRawBytes(); err != nil {
+					return nil, err
+				}
+				w.WriteBytes(tmpBytes)
+			}
+		}
+
+		if *scheduleEvent.DurationFlag {
+			if scheduleEvent.BreakDuration == nil {
+				return nil, MissingFieldError("break_duration")
+			}
+			if tmpBytes, err = scheduleEvent.BreakDuration.EncodeToRawBytes(); err != nil {
+				return nil, err
+			}
+			w.WriteBytes(tmpBytes)
+		}
 
-	breakDuration.AutoReturn, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
+		if scheduleEvent.UniqueProgramID == nil {
+			return nil, MissingFieldError("unique_program_id")
+		}
+		if scheduleEvent.AvailNum == nil {
+			return nil, MissingFieldError("avail_num")
+		}
+		if scheduleEvent.AvailsExpected == nil {
+			return nil, MissingFieldError("avails_expected")
+		}
+		w.WriteBits(uint64(*scheduleEvent.UniqueProgramID), 16)
+		w.WriteBits(uint64(*scheduleEvent.AvailNum), 8)
+		w.WriteBits(uint64(*scheduleEvent.AvailsExpected), 8)
+	}
 
-	numOfParsedBits += 6 //reserved 6 bits
+	return w.Bytes()
+}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 33)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 7)
-	tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-	breakDuration.Duration, _, err = bits.Uint64(tmpBytes, 0)
-	numOfParsedBits += 33
+//EncodeToRawBytes serializes SpliceSchedule object to []byte
+func (spliceSchedule *SpliceSchedule) EncodeToRawBytes() (output []byte, err error) {
+	var tmpBytes []byte
+	w := &BitWriter{}
+
+	var scheduleEvents []ScheduleEvent
+	if spliceSchedule.ScheduleEvents != nil {
+		scheduleEvents = *spliceSchedule.ScheduleEvents
+	}
+	if len(scheduleEvents) > 0xff {
+		return nil, errors.New("Encode Error: splice_schedule has more than 255 events")
+	}
+	spliceSchedule.SpliceCount = uint8(len(scheduleEvents))
+	w.WriteBits(uint64(spliceSchedule.SpliceCount), 8)
 
-	if err != nil {
-		return 0, err
+	for i := range scheduleEvents {
+		if tmpBytes, err = scheduleEvents[i].EncodeToRawBytes(); err != nil {
+			return nil, err
+		}
+		w.WriteBytes(tmpBytes)
 	}
-	return numOfParsedBits, err
+
+	return w.Bytes()
 }
 
-//DecodeFromRawBytes parses input []byte to SpliceTime object
-func (spliceTime *SpliceTime) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+//EncodeToRawBytes serializes InsertComponent object to []byte
+func (insertComponent *InsertComponent) EncodeToRawBytes(spliceImmediateFlag bool) (output []byte, err error) {
 	var tmpBytes []byte
+	w := &BitWriter{}
 
-	spliceTime.TimeSpecifiedFlag, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
+	w.WriteBits(uint64(insertComponent.ComponentTag), 8)
 
-	if spliceTime.TimeSpecifiedFlag {
-		numOfParsedBits += 6 //reserved 6 bits
+	if !spliceImmediateFlag {
+		if insertComponent.SpliceTime == nil {
+			return nil, MissingFieldError("insert_components.splice_time")
+		}
+		if tmpBytes, err = insertComponent.SpliceTime.EncodeToRawBytes(); err != nil {
+			return nil, err
+		}
+		w.WriteBytes(tmpBytes)
+	}
 
-		tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 33)
-		tmpBytes, _ = bits.ShiftRight(tmpBytes, 7)
-		tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-		_, spliceTime.PTSTime, err = bits.Uint64(tmpBytes, 0)
-		numOfParsedBits += 33
+	return w.Bytes()
+}
+
+//EncodeToRawBytes serializes ScheduleComponent object to []byte
+func (scheduleComponent *ScheduleComponent) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
+
+	w.WriteBits(uint64(scheduleComponent.ComponentTag), 8)
+	w.WriteBits(uint64(scheduleComponent.UTCSpliceTime), 32)
+
+	return w.Bytes()
+}
+
+//EncodeToRawBytes serializes BreakDuration object to []byte
+func (breakDuration *BreakDuration) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
+
+	w.WriteBool(breakDuration.AutoReturn)
+	w.WriteReserved(6)
+	w.WriteBits(breakDuration.Duration, 33)
+
+	return w.Bytes()
+}
+
+//EncodeToRawBytes serializes SpliceTime object to []byte
+func (spliceTime *SpliceTime) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
+
+	w.WriteBool(spliceTime.TimeSpecifiedFlag)
+	if spliceTime.TimeSpecifiedFlag {
+		if spliceTime.PTSTime == nil {
+			return nil, MissingFieldError("pts_time")
+		}
+		w.WriteReserved(6)
+		w.WriteBits(*spliceTime.PTSTime, 33)
 	} else {
-		numOfParsedBits += 7 //reserved 7 bits
+		w.WriteReserved(7)
 	}
 
-	return numOfParsedBits, err
+	return w.Bytes()
 }
 
-//DecodeFromRawBytes parses input []byte to TimeSignal object
-func (timeSignal *TimeSignal) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	timeSignal.SpliceTime = &SpliceTime{}
-	numOfParsedBits, err = timeSignal.SpliceTime.DecodeFromRawBytes(input)
-
-	return numOfParsedBits, err
+//EncodeToRawBytes serializes TimeSignal object to []byte
+func (timeSignal *TimeSignal) EncodeToRawBytes() (output []byte, err error) {
+	if timeSignal.SpliceTime == nil {
+		return nil, MissingFieldError("splice_time")
+	}
+	return timeSignal.SpliceTime.EncodeToRawBytes()
 }
@@ -1,7 +1,8 @@
 package common
 
 import (
-	bits "github.com/chanyk-joseph/gobits"
+	"errors"
+	"strconv"
 )
 
 type AvailDescriptor struct {
@@ -22,21 +23,22 @@ type SegmentationDescriptor struct {
 	SegmentationDurationFlag  *bool `json:"segmentation_duration_flag,omitempty"`
 	DeliveryNotRestrictedFlag *bool `json:"delivery_not_restricted_flag,omitempty"`
 
-	WebDeliveryAllowedFlag *bool  `json:"web_delivery_allowed_flag,omitempty"`
-	NoRegionalBlackoutFlag *bool  `json:"no_regional_blackout_flag,omitempty"`
-	ArchiveAllowedFlag     *bool  `json:"archive_allowed_flag,omitempty"`
-	DeviceRestrictions     *uint8 `json:"device_restrictions,omitempty"` //2 bits
+	WebDeliveryAllowedFlag *bool               `json:"web_delivery_allowed_flag,omitempty"`
+	NoRegionalBlackoutFlag *bool               `json:"no_regional_blackout_flag,omitempty"`
+	ArchiveAllowedFlag     *bool               `json:"archive_allowed_flag,omitempty"`
+	DeviceRestrictions     *DeviceRestrictions `json:"device_restrictions,omitempty"` //2 bits
 
 	ComponentCount         *uint8                   `json:"component_count,omitempty"`
 	SegmentationComponents *[]SegmentationComponent `json:"segmentation_components,omitempty"` //2 bits
 
-	SegmentationDuration   *uint64 `json:"segmentation_duration,omitempty"` //40 bits
-	SegmentationUpidType   *byte   `json:"segmentation_upid_type,omitempty"`
-	SegmentationUpidLength *uint8  `json:"segmentation_upid_length,omitempty"`
-	SegmentationUpidInHex  *string `json:"segmentation_upid_in_hex,omitempty"`
-	SegmentationTypeID     *uint8  `json:"segmentation_type_id,omitempty"`
-	SegmentNum             *uint8  `json:"segment_num,omitempty"`
-	SegmentsExpected       *uint8  `json:"segments_expected,omitempty"`
+	SegmentationDuration   *uint64               `json:"segmentation_duration,omitempty"` //40 bits
+	SegmentationUpidType   *SegmentationUpidType `json:"segmentation_upid_type,omitempty"`
+	SegmentationUpidLength *uint8                `json:"segmentation_upid_length,omitempty"`
+	SegmentationUpidInHex  *string               `json:"segmentation_upid_in_hex,omitempty"`
+	SegmentationUpid       *SegmentationUpid     `json:"segmentation_upid,omitempty"` //typed value of segmentation_upid_in_hex
+	SegmentationTypeID     *SegmentationTypeID   `json:"segmentation_type_id,omitempty"`
+	SegmentNum             *uint8                `json:"segment_num,omitempty"`
+	SegmentsExpected       *uint8                `json:"segments_expected,omitempty"`
 }
 
 type SegmentationComponent struct {
@@ -51,136 +53,290 @@ type TimeDescriptor struct {
 }
 
 func (segDesc *SegmentationDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
-
-	segDesc.SegmentationEventID, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
-
-	segDesc.SegmentationEventCancelIndicator, _, err = bits.Bool(input, numOfParsedBits)
-	numOfParsedBits++
+	r := NewBitReader(input)
+	segDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
 
-	numOfParsedBits += 7 //reserved 7 bits
+//DecodeFromBitReader parses SegmentationDescriptor object from r
+func (segDesc *SegmentationDescriptor) DecodeFromBitReader(r *BitReader) {
+	segDesc.SegmentationEventID = r.Uint32("segmentation_event_id")
+	segDesc.SegmentationEventCancelIndicator = r.Bool("segmentation_event_cancel_indicator")
+	r.Reserved(7)
 
 	if !segDesc.SegmentationEventCancelIndicator {
-		_, segDesc.ProgramSegmentationFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		programSegmentationFlag := r.Bool("program_segmentation_flag")
+		segDesc.ProgramSegmentationFlag = &programSegmentationFlag
 
-		_, segDesc.SegmentationDurationFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		segmentationDurationFlag := r.Bool("segmentation_duration_flag")
+		segDesc.SegmentationDurationFlag = &segmentationDurationFlag
 
-		_, segDesc.DeliveryNotRestrictedFlag, err = bits.Bool(input, numOfParsedBits)
-		numOfParsedBits++
+		deliveryNotRestrictedFlag := r.Bool("delivery_not_restricted_flag")
+		segDesc.DeliveryNotRestrictedFlag = &deliveryNotRestrictedFlag
 
-		if !*segDesc.DeliveryNotRestrictedFlag {
-			_, segDesc.WebDeliveryAllowedFlag, err = bits.Bool(input, numOfParsedBits)
-			numOfParsedBits++
+		if !deliveryNotRestrictedFlag {
+			webDeliveryAllowedFlag := r.Bool("web_delivery_allowed_flag")
+			segDesc.WebDeliveryAllowedFlag = &webDeliveryAllowedFlag
 
-			_, segDesc.NoRegionalBlackoutFlag, err = bits.Bool(input, numOfParsedBits)
-			numOfParsedBits++
+			noRegionalBlackoutFlag := r.Bool("no_regional_blackout_flag")
+			segDesc.NoRegionalBlackoutFlag = &noRegionalBlackoutFlag
 
-			_, segDesc.ArchiveAllowedFlag, err = bits.Bool(input, numOfParsedBits)
-			numOfParsedBits++
+			archiveAllowedFlag := r.Bool("archive_allowed_flag")
+			segDesc.ArchiveAllowedFlag = &archiveAllowedFlag
 
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 2)
-			tmpBytes, _ = bits.ShiftRight(tmpBytes, 6)
-			_, segDesc.DeviceRestrictions, err = bits.Uint8(tmpBytes, 0)
-			numOfParsedBits += 2
+			deviceRestrictions := DeviceRestrictions(r.Bits("device_restrictions", 2))
+			segDesc.DeviceRestrictions = &deviceRestrictions
 		} else {
-			numOfParsedBits += 5
+			r.Reserved(5)
 		}
 
-		if !*segDesc.ProgramSegmentationFlag {
-			_, segDesc.ComponentCount, err = bits.Uint8(input, numOfParsedBits)
-			numOfParsedBits += 8
+		if !programSegmentationFlag {
+			componentCount := r.Uint8("component_count")
+			segDesc.ComponentCount = &componentCount
 
 			var components []SegmentationComponent
-			for i := 0; i < int(*segDesc.ComponentCount); i++ {
+			for i := 0; i < int(componentCount) && r.Err() == nil; i++ {
 				segComp := SegmentationComponent{}
+				sub := r.Sub("segmentation_components["+strconv.Itoa(i)+"]", 48)
 
-				segComp.ComponentTag, _, err = bits.Byte(input, numOfParsedBits)
-				numOfParsedBits += 8
-
-				numOfParsedBits += 7 //reserved 7 bits
-
-				tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 33)
-				tmpBytes, _ = bits.ShiftRight(tmpBytes, 7)
-				tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-				segComp.PTSOffset, _, err = bits.Uint64(tmpBytes, 0)
-				numOfParsedBits += 33
+				segComp.ComponentTag = sub.Uint8("component_tag")
+				sub.Reserved(7)
+				segComp.PTSOffset = sub.Bits("pts_offset", 33)
+				r.Merge(sub)
 
 				components = append(components, segComp)
 			}
 			segDesc.SegmentationComponents = &components
 		}
 
-		if *segDesc.SegmentationDurationFlag {
-			tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 40)
-			tmpBytes = append([]byte{0x00, 0x00, 0x00}, tmpBytes...)
-			_, segDesc.SegmentationDuration, err = bits.Uint64(tmpBytes, 0)
-			numOfParsedBits += 40
+		if segmentationDurationFlag {
+			segmentationDuration := r.Bits("segmentation_duration", 40)
+			segDesc.SegmentationDuration = &segmentationDuration
 		}
 
-		_, segDesc.SegmentationUpidType, err = bits.Byte(input, numOfParsedBits)
-		numOfParsedBits += 8
+		segmentationUpidType := SegmentationUpidType(r.Uint8("segmentation_upid_type"))
+		segDesc.SegmentationUpidType = &segmentationUpidType
 
-		_, segDesc.SegmentationUpidLength, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
+		segmentationUpidLength := r.Uint8("segmentation_upid_length")
+		segDesc.SegmentationUpidLength = &segmentationUpidLength
 
-		if int(*segDesc.SegmentationUpidLength) > 0 {
-			_, segDesc.SegmentationUpidInHex, err = bits.HexString(input, numOfParsedBits, int(*segDesc.SegmentationUpidLength)*8)
-			numOfParsedBits += (int(*segDesc.SegmentationUpidLength) * 8)
+		if int(segmentationUpidLength) > 0 {
+			segmentationUpidInHex := r.HexString("segmentation_upid", int(segmentationUpidLength))
+			segDesc.SegmentationUpidInHex = &segmentationUpidInHex
+		}
+		if r.Err() == nil {
+			segDesc.DecodeSegmentationUpid()
 		}
 
-		_, segDesc.SegmentationTypeID, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
+		segmentationTypeID := SegmentationTypeID(r.Uint8("segmentation_type_id"))
+		segDesc.SegmentationTypeID = &segmentationTypeID
 
-		_, segDesc.SegmentNum, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
+		segmentNum := r.Uint8("segment_num")
+		segDesc.SegmentNum = &segmentNum
 
-		_, segDesc.SegmentsExpected, err = bits.Uint8(input, numOfParsedBits)
-		numOfParsedBits += 8
+		segmentsExpected := r.Uint8("segments_expected")
+		segDesc.SegmentsExpected = &segmentsExpected
 	}
-
-	return numOfParsedBits, err
 }
 
 func (availDesc *AvailDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	availDesc.ProviderAvailID, _, err = bits.Uint32(input, 1)
-	return 32, err
+	r := NewBitReader(input)
+	availDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses AvailDescriptor object from r
+func (availDesc *AvailDescriptor) DecodeFromBitReader(r *BitReader) {
+	availDesc.ProviderAvailID = r.Uint32("provider_avail_id")
 }
 
 func (dtmfDesc *DTMFDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := NewBitReader(input)
+	dtmfDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses DTMFDescriptor object from r
+func (dtmfDesc *DTMFDescriptor) DecodeFromBitReader(r *BitReader) {
+	dtmfDesc.Preroll = r.Uint8("preroll")
+	dtmfDesc.DTMFCount = uint8(r.Bits("dtmf_count", 3))
+	r.Reserved(5)
+	dtmfDesc.DTMFChars = r.String("dtmf_chars", int(dtmfDesc.DTMFCount))
+}
+
+func (timeDesc *TimeDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	r := NewBitReader(input)
+	timeDesc.DecodeFromBitReader(r)
+	return r.Result()
+}
+
+//DecodeFromBitReader parses TimeDescriptor object from r
+func (timeDesc *TimeDescriptor) DecodeFromBitReader(r *BitReader) {
+	timeDesc.TAI_seconds = r.Bits("tai_seconds", 48)
+	timeDesc.TAI_ns = r.Uint32("tai_ns")
+	timeDesc.UTC_offset = r.Uint16("utc_offset")
+}
+
+//EncodeToRawBytes serializes SegmentationDescriptor object to []byte
+func (segDesc *SegmentationDescriptor) EncodeToRawBytes() (output []byte, err error) {
 	var tmpBytes []byte
+	w := &BitWriter{}
+
+	w.WriteBits(uint64(segDesc.SegmentationEventID), 32)
+	w.WriteBool(segDesc.SegmentationEventCancelIndicator)
+	w.WriteReserved(7)
+
+	if !segDesc.SegmentationEventCancelIndicator {
+		if segDesc.ProgramSegmentationFlag == nil {
+			return nil, MissingFieldError("program_segmentation_flag")
+		}
+		if segDesc.SegmentationDurationFlag == nil {
+			return nil, MissingFieldError("segmentation_duration_flag")
+		}
+		if segDesc.DeliveryNotRestrictedFlag == nil {
+			return nil, MissingFieldError("delivery_not_restricted_flag")
+		}
+		w.WriteBool(*segDesc.ProgramSegmentationFlag)
+		w.WriteBool(*segDesc.SegmentationDurationFlag)
+		w.WriteBool(*segDesc.DeliveryNotRestrictedFlag)
+
+		if !*segDesc.DeliveryNotRestrictedFlag {
+			if segDesc.WebDeliveryAllowedFlag == nil {
+				return nil, MissingFieldError("web_delivery_allowed_flag")
+			}
+			if segDesc.NoRegionalBlackoutFlag == nil {
+				return nil, MissingFieldError("no_regional_blackout_flag")
+			}
+			if segDesc.ArchiveAllowedFlag == nil {
+				return nil, MissingFieldError("archive_allowed_flag")
+			}
+			if segDesc.DeviceRestrictions == nil {
+				return nil, MissingFieldError("device_restrictions")
+			}
+			w.WriteBool(*segDesc.WebDeliveryAllowedFlag)
+			w.WriteBool(*segDesc.NoRegionalBlackoutFlag)
+			w.WriteBool(*segDesc.ArchiveAllowedFlag)
+			w.WriteBits(uint64(*segDesc.DeviceRestrictions), 2)
+		} else {
+			w.WriteReserved(5)
+		}
 
-	dtmfDesc.Preroll, _, err = bits.Byte(input, numOfParsedBits)
-	numOfParsedBits += 8
+		if !*segDesc.ProgramSegmentationFlag {
+			var components []SegmentationComponent
+			if segDesc.SegmentationComponents != nil {
+				components = *segDesc.SegmentationComponents
+			}
+			if len(components) > 0xff {
+				return nil, errors.New("Encode Error: segmentation_descriptor has more than 255 components")
+			}
+			componentCount := uint8(len(components))
+			segDesc.ComponentCount = &componentCount
+			w.WriteBits(uint64(componentCount), 8)
+
+			for _, segComp := range components {
+				w.WriteBits(uint64(segComp.ComponentTag), 8)
+				w.WriteReserved(7)
+				w.WriteBits(segComp.PTSOffset, 33)
+			}
+		}
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 3)
-	tmpBytes, _ = bits.ShiftRight(tmpBytes, 5)
-	dtmfDesc.DTMFCount, _, err = bits.Uint8(tmpBytes, 0)
-	numOfParsedBits += 3
+		if *segDesc.SegmentationDurationFlag {
+			if segDesc.SegmentationDuration == nil {
+				return nil, MissingFieldError("segmentation_duration")
+			}
+			w.WriteBits(*segDesc.SegmentationDuration, 40)
+		}
+
+		if segDesc.SegmentationUpidType == nil {
+			return nil, MissingFieldError("segmentation_upid_type")
+		}
+		w.WriteBits(uint64(*segDesc.SegmentationUpidType), 8)
+
+		upidWriter := &BitWriter{}
+		if segDesc.SegmentationUpidInHex != nil {
+			if err = upidWriter.WriteHexString(*segDesc.SegmentationUpidInHex); err != nil {
+				return nil, err
+			}
+		} else if segDesc.SegmentationUpid != nil {
+			//the typed value is only used when segmentation_upid_in_hex is absent
+			if segDesc.SegmentationUpid.Upid == nil {
+				return nil, errors.New("Encode Error: segmentation_upid is not a valid typed value of segmentation_upid_type " + strconv.Itoa(int(*segDesc.SegmentationUpidType)) + " and segmentation_upid_in_hex is absent")
+			}
+			if segDesc.SegmentationUpid.UpidType() != *segDesc.SegmentationUpidType {
+				return nil, errors.New("Encode Error: segmentation_upid_type is " + strconv.Itoa(int(*segDesc.SegmentationUpidType)) + " but segmentation_upid is a typed value of type " + strconv.Itoa(int(segDesc.SegmentationUpid.UpidType())))
+			}
+			if tmpBytes, err = segDesc.SegmentationUpid.EncodeToRawBytes(); err != nil {
+				return nil, err
+			}
+			upidWriter.WriteBytes(tmpBytes)
+		}
+		upidBytes, _ := upidWriter.Bytes()
+		if len(upidBytes) > 0xff {
+			return nil, errors.New("Encode Error: segmentation_upid is longer than 255 bytes")
+		}
+		upidLength := uint8(len(upidBytes))
+		segDesc.SegmentationUpidLength = &upidLength
+		w.WriteBits(uint64(upidLength), 8)
+		w.WriteBytes(upidBytes)
 
-	numOfParsedBits += 5 // reserved 5 bits
+		if segDesc.SegmentationTypeID == nil {
+			return nil, MissingFieldError("segmentation_type_id")
+		}
+		if segDesc.SegmentNum == nil {
+			return nil, MissingFieldError("segment_num")
+		}
+		if segDesc.SegmentsExpected == nil {
+			return nil, MissingFieldError("segments_expected")
+		}
+		w.WriteBits(uint64(*segDesc.SegmentationTypeID), 8)
+		w.WriteBits(uint64(*segDesc.SegmentNum), 8)
+		w.WriteBits(uint64(*segDesc.SegmentsExpected), 8)
+	}
 
-	dtmfDesc.DTMFChars, _, err = bits.String(input, numOfParsedBits, int(dtmfDesc.DTMFCount)*8)
-	numOfParsedBits += int(dtmfDesc.DTMFCount) * 8
+	return w.Bytes()
+}
 
-	return numOfParsedBits, err
+//EncodeToRawBytes serializes AvailDescriptor object to []byte
+func (availDesc *AvailDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
+	w.WriteBits(uint64(availDesc.ProviderAvailID), 32)
+	return w.Bytes()
 }
 
-func (timeDesc *TimeDescriptor) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
-	var tmpBytes []byte
+//EncodeToRawBytes serializes DTMFDescriptor object to []byte
+func (dtmfDesc *DTMFDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
+
+	if len(dtmfDesc.DTMFChars) > 7 {
+		return nil, errors.New("Encode Error: dtmf_chars is longer than 7 characters")
+	}
+	dtmfDesc.DTMFCount = uint8(len(dtmfDesc.DTMFChars))
+
+	w.WriteBits(uint64(dtmfDesc.Preroll), 8)
+	w.WriteBits(uint64(dtmfDesc.DTMFCount), 3)
+	w.WriteReserved(5)
+	w.WriteBytes([]byte(dtmfDesc.DTMFChars))
 
-	tmpBytes, _, err = bits.SubBits(input, numOfParsedBits, 48)
-	tmpBytes = append([]byte{0x00, 0x00}, tmpBytes...)
-	timeDesc.TAI_seconds, _, err = bits.Uint64(tmpBytes, 0)
-	numOfParsedBits += 48
+	return w.Bytes()
+}
+
+//EncodeToRawBytes serializes TimeDescriptor object to []byte
+func (timeDesc *TimeDescriptor) EncodeToRawBytes() (output []byte, err error) {
+	w := &BitWriter{}
 
-	timeDesc.TAI_ns, _, err = bits.Uint32(input, numOfParsedBits)
-	numOfParsedBits += 32
+	w.WriteBits(timeDesc.TAI_seconds, 48)
+	w.WriteBits(uint64(timeDesc.TAI_ns), 32)
+	w.WriteBits(uint64(timeDesc.UTC_offset), 16)
 
-	timeDesc.UTC_offset, _, err = bits.Uint16(input, numOfParsedBits)
-	numOfParsedBits += 16
+	return w.Bytes()
+}
 
-	return numOfParsedBits, err
+//CheckEncodedBody returns an error if a CUEI splice descriptor would be encoded without a body,
+//i.e. body written for the typed descriptor of its tag is empty(the tag is not supported by schemaVersion, or the typed descriptor is nil) and there are no private bytes
+//Descriptors of other identifiers may be empty
+func (spliceDesc *SpliceDescriptor) CheckEncodedBody(body []byte, schemaVersion string) error {
+	if len(body) > 0 || spliceDesc.PrivateByteInHex != nil || spliceDesc.Identifier != CUEIIdentifier {
+		return nil
+	}
+	return errors.New("Encode Error: " + spliceDesc.SpliceDescriptorTag.String() + "(tag: " + strconv.Itoa(int(spliceDesc.SpliceDescriptorTag)) + ") has neither a descriptor encodable by schema " + schemaVersion + " nor private_byte_in_hex")
 }
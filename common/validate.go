@@ -0,0 +1,199 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Severity of a Finding reported by Validate()
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+//Finding is a spec violation(or a notable point) of a decoded splice_info_section
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"` //e.g. splice_descriptors[1].segmentation_descriptor.segment_num
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	return "[" + f.Severity.String() + "] " + f.Path + ": " + f.Message
+}
+
+//segmentationTypeIDsExpectingDuration are the start types whose segmentation_duration should be present
+var segmentationTypeIDsExpectingDuration = map[SegmentationTypeID]bool{
+	0x30: true, 0x32: true, 0x34: true, 0x36: true, 0x38: true, 0x3A: true, 0x44: true, 0x46: true,
+}
+
+//RawBytes returns the input of the last successful DecodeFromRawBytes(with the encrypted portion decrypted), it is used by Validate() to check reserved bits
+func (scte35 *SCTE35) RawBytes() []byte {
+	return scte35.rawBytes
+}
+
+//SetRawBytes records the raw splice_info_section the object is decoded from
+func (scte35 *SCTE35) SetRawBytes(input []byte) {
+	scte35.rawBytes = input
+}
+
+//ValidateSection checks the fields of splice_info_section which are common to all schemas
+func ValidateSection(scte35 *SCTE35) (findings []Finding) {
+	if scte35.TableID != 0xFC {
+		findings = append(findings, Finding{SeverityError, "table_id", "must be 0xFC, got 0x" + strconv.FormatUint(uint64(scte35.TableID), 16)})
+	}
+	if scte35.SectionSyntaxIndicator {
+		findings = append(findings, Finding{SeverityError, "section_syntax_indicator", "must be 0"})
+	}
+	if scte35.PrivateIndicator {
+		findings = append(findings, Finding{SeverityError, "private_indicator", "must be 0"})
+	}
+	if scte35.ProtocolVersion != 0 {
+		findings = append(findings, Finding{SeverityError, "protocol_version", "must be 0, got " + strconv.Itoa(int(scte35.ProtocolVersion))})
+	}
+	if !scte35.EncryptedPacket && scte35.EncryptionAlgorithm != 0 {
+		findings = append(findings, Finding{SeverityWarning, "encryption_algorithm", "is " + strconv.Itoa(int(scte35.EncryptionAlgorithm)) + " but encrypted_packet is 0"})
+	}
+	if scte35.EncryptedPacket && scte35.EncryptionAlgorithm > 3 && scte35.EncryptionAlgorithm < 32 {
+		findings = append(findings, Finding{SeverityError, "encryption_algorithm", strconv.Itoa(int(scte35.EncryptionAlgorithm)) + " is reserved"})
+	}
+	if scte35.SpliceCommandType.String() == "reserved" {
+		findings = append(findings, Finding{SeverityError, "splice_command_type", strconv.Itoa(int(scte35.SpliceCommandType)) + " is reserved"})
+	}
+	if scte35.crc32Mismatch != nil {
+		findings = append(findings, Finding{SeverityError, "crc_32", scte35.crc32Mismatch.Error()})
+	}
+	return findings
+}
+
+//ValidateReservedBits compares the raw section with its re-encoded form, in which all reserved bits are 1
+//CRC_32 and E_CRC_32 are excluded, as they are recalculated by the encoder
+func ValidateReservedBits(raw []byte, encoded []byte, encrypted bool) (findings []Finding) {
+	if len(raw) != len(encoded) {
+		return append(findings, Finding{SeverityInfo, "", "re-encoded section has " + strconv.Itoa(len(encoded)) + " bytes while the input has " + strconv.Itoa(len(raw)) + " bytes, reserved bits are not checked"})
+	}
+
+	endPos := len(raw) - 4
+	if encrypted {
+		endPos -= 4
+	}
+
+	var offsets []string
+	for i := 0; i < endPos*8; i++ {
+		mask := byte(0x80) >> uint(i%8)
+		if raw[i/8]&mask == 0 && encoded[i/8]&mask != 0 {
+			offsets = append(offsets, strconv.Itoa(i))
+		}
+	}
+	if len(offsets) > 0 {
+		findings = append(findings, Finding{SeverityWarning, "reserved", "reserved bits must be 1, " + strconv.Itoa(len(offsets)) + " bits are 0 at bit offsets " + strings.Join(offsets, ",")})
+	}
+	return findings
+}
+
+//ValidateSpliceDescriptor checks the fields shared by all splice descriptors
+func ValidateSpliceDescriptor(spliceDesc *SpliceDescriptor, path string) (findings []Finding) {
+	if spliceDesc.DecodeError != nil {
+		findings = append(findings, Finding{SeverityError, path, "unable to decode, kept as undecoded bytes: " + *spliceDesc.DecodeError})
+	}
+	if spliceDesc.Identifier != CUEIIdentifier {
+		if spliceDesc.SpliceDescriptorTag <= 0x04 {
+			findings = append(findings, Finding{SeverityWarning, path + ".identifier", "is " + strconv.Quote(FourCC(spliceDesc.Identifier)) + " instead of \"CUEI\", the descriptor is decoded as " + spliceDesc.SpliceDescriptorTag.String()})
+		} else {
+			findings = append(findings, Finding{SeverityInfo, path + ".identifier", "private descriptor of " + strconv.Quote(FourCC(spliceDesc.Identifier))})
+		}
+	} else if spliceDesc.SpliceDescriptorTag > 0x04 {
+		findings = append(findings, Finding{SeverityWarning, path + ".splice_descriptor_tag", strconv.Itoa(int(spliceDesc.SpliceDescriptorTag)) + " is reserved for CUEI descriptors"})
+	}
+	return findings
+}
+
+//ValidateSpliceInsert checks splice_insert
+func ValidateSpliceInsert(spliceInsert *SpliceInsert, path string) (findings []Finding) {
+	if spliceInsert.SpliceEventCancelIndicator {
+		return findings
+	}
+
+	if spliceInsert.AvailNum != nil && spliceInsert.AvailsExpected != nil && *spliceInsert.AvailsExpected != 0 && *spliceInsert.AvailNum > *spliceInsert.AvailsExpected {
+		findings = append(findings, Finding{SeverityWarning, path + ".avail_num", strconv.Itoa(int(*spliceInsert.AvailNum)) + " is more than avails_expected(" + strconv.Itoa(int(*spliceInsert.AvailsExpected)) + ")"})
+	}
+	if spliceInsert.BreakDuration != nil && spliceInsert.BreakDuration.Duration == 0 {
+		findings = append(findings, Finding{SeverityWarning, path + ".break_duration.duration", "duration_flag is set but duration is 0"})
+	}
+	if spliceInsert.OutOfNetworkIndicator != nil && !*spliceInsert.OutOfNetworkIndicator && spliceInsert.BreakDuration != nil {
+		findings = append(findings, Finding{SeverityInfo, path + ".break_duration", "is present while out_of_network_indicator is 0"})
+	}
+	if spliceInsert.ProgramSpliceFlag != nil && !*spliceInsert.ProgramSpliceFlag {
+		findings = append(findings, Finding{SeverityInfo, path + ".program_splice_flag", "component splice mode is deprecated"})
+	}
+	return findings
+}
+
+//ValidateDTMFDescriptor checks DTMF_descriptor
+func ValidateDTMFDescriptor(dtmfDesc *DTMFDescriptor, path string) (findings []Finding) {
+	if strings.Trim(dtmfDesc.DTMFChars, "0123456789*#") != "" {
+		findings = append(findings, Finding{SeverityWarning, path + ".dtmf_chars", strconv.Quote(dtmfDesc.DTMFChars) + " contains characters other than 0-9, * and #"})
+	}
+	return findings
+}
+
+//ValidateSegmentationDescriptor checks segmentation_descriptor
+func ValidateSegmentationDescriptor(segDesc *SegmentationDescriptor, path string) (findings []Finding) {
+	if segDesc.SegmentationEventCancelIndicator {
+		return findings
+	}
+
+	if segDesc.SegmentNum != nil && segDesc.SegmentsExpected != nil && *segDesc.SegmentsExpected != 0 && *segDesc.SegmentNum > *segDesc.SegmentsExpected {
+		findings = append(findings, Finding{SeverityError, path + ".segment_num", strconv.Itoa(int(*segDesc.SegmentNum)) + " is more than segments_expected(" + strconv.Itoa(int(*segDesc.SegmentsExpected)) + ")"})
+	}
+
+	if segDesc.SegmentationTypeID != nil {
+		typeID := *segDesc.SegmentationTypeID
+		if typeID.String() == "Reserved" {
+			findings = append(findings, Finding{SeverityWarning, path + ".segmentation_type_id", "0x" + strconv.FormatUint(uint64(typeID), 16) + " is reserved"})
+		}
+		if segmentationTypeIDsExpectingDuration[typeID] && segDesc.SegmentationDuration == nil {
+			findings = append(findings, Finding{SeverityWarning, path + ".segmentation_duration", "should be present for " + typeID.String()})
+		}
+	}
+
+	if segDesc.SegmentationUpidType != nil {
+		if segDesc.SegmentationUpidType.String() == "Reserved" {
+			findings = append(findings, Finding{SeverityWarning, path + ".segmentation_upid_type", "0x" + strconv.FormatUint(uint64(*segDesc.SegmentationUpidType), 16) + " is reserved"})
+		}
+		if *segDesc.SegmentationUpidType == 0x00 && segDesc.SegmentationUpidLength != nil && *segDesc.SegmentationUpidLength != 0 {
+			findings = append(findings, Finding{SeverityWarning, path + ".segmentation_upid_length", "must be 0 when segmentation_upid_type is 0x00(Not Used)"})
+		}
+		if _, err := segDesc.Upid(); err != nil {
+			findings = append(findings, Finding{SeverityWarning, path + ".segmentation_upid", err.Error()})
+		}
+	}
+	return findings
+}
+
+//ValidateSubSegments checks sub_segment_num against sub_segments_expected
+func ValidateSubSegments(subSegmentNum *uint8, subSegmentsExpected *uint8, path string) (findings []Finding) {
+	if subSegmentNum != nil && subSegmentsExpected != nil && *subSegmentsExpected != 0 && *subSegmentNum > *subSegmentsExpected {
+		findings = append(findings, Finding{SeverityError, path + ".sub_segment_num", strconv.Itoa(int(*subSegmentNum)) + " is more than sub_segments_expected(" + strconv.Itoa(int(*subSegmentsExpected)) + ")"})
+	}
+	return findings
+}
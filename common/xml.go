@@ -0,0 +1,714 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//XMLNamespace is the namespace of the XML schema of SCTE35
+const XMLNamespace = "http://www.scte.org/schemas/35"
+
+//XMLSpliceInfoSection is the SpliceInfoSection element of the XML schema of SCTE35
+//The elements are matched by their local names, so that documents of the older namespaces(e.g. http://www.scte.org/schemas/35/2016) are accepted
+type XMLSpliceInfoSection struct {
+	XMLName         xml.Name `xml:"SpliceInfoSection"`
+	Xmlns           string   `xml:"xmlns,attr,omitempty"`
+	PTSAdjustment   uint64   `xml:"ptsAdjustment,attr"`
+	ProtocolVersion uint8    `xml:"protocolVersion,attr"`
+	Tier            uint16   `xml:"tier,attr"`
+
+	EncryptedPacket *XMLEncryptedPacket `xml:"EncryptedPacket"`
+
+	SpliceNull           *XMLEmpty          `xml:"SpliceNull"`
+	SpliceSchedule       *XMLSpliceSchedule `xml:"SpliceSchedule"`
+	SpliceInsert         *XMLSpliceInsert   `xml:"SpliceInsert"`
+	TimeSignal           *XMLTimeSignal     `xml:"TimeSignal"`
+	BandwidthReservation *XMLEmpty          `xml:"BandwidthReservation"`
+	PrivateCommand       *XMLPrivateCommand `xml:"PrivateCommand"`
+
+	//SpliceDescriptors are in the order of the document, the kind of each one is given by its XMLName
+	SpliceDescriptors []XMLSpliceDescriptor `xml:",any"`
+}
+
+type XMLEmpty struct{}
+
+type XMLEncryptedPacket struct {
+	EncryptionAlgorithm byte  `xml:"encryptionAlgorithm,attr"`
+	CWIndex             uint8 `xml:"cwIndex,attr"`
+}
+
+type XMLSpliceSchedule struct {
+	Events []XMLScheduleEvent `xml:"Event"`
+}
+
+type XMLScheduleEvent struct {
+	SpliceEventID              uint32  `xml:"spliceEventId,attr"`
+	SpliceEventCancelIndicator bool    `xml:"spliceEventCancelIndicator,attr"`
+	OutOfNetworkIndicator      *bool   `xml:"outOfNetworkIndicator,attr"`
+	UniqueProgramID            *uint16 `xml:"uniqueProgramId,attr"`
+	AvailNum                   *byte   `xml:"availNum,attr"`
+	AvailsExpected             *byte   `xml:"availsExpected,attr"`
+
+	Program       *XMLScheduleProgram    `xml:"Program"`
+	Components    []XMLScheduleComponent `xml:"Component"`
+	BreakDuration *XMLBreakDuration      `xml:"BreakDuration"`
+}
+
+type XMLScheduleProgram struct {
+	UTCSpliceTime uint32 `xml:"utcSpliceTime,attr"`
+}
+
+type XMLScheduleComponent struct {
+	ComponentTag  byte   `xml:"componentTag,attr"`
+	UTCSpliceTime uint32 `xml:"utcSpliceTime,attr"`
+}
+
+type XMLSpliceInsert struct {
+	SpliceEventID              uint32  `xml:"spliceEventId,attr"`
+	SpliceEventCancelIndicator bool    `xml:"spliceEventCancelIndicator,attr"`
+	OutOfNetworkIndicator      *bool   `xml:"outOfNetworkIndicator,attr"`
+	SpliceImmediateFlag        *bool   `xml:"spliceImmediateFlag,attr"`
+	EventIDComplianceFlag      *bool   `xml:"eventIdComplianceFlag,attr"` //added since SCTE35 2019
+	UniqueProgramID            *uint16 `xml:"uniqueProgramId,attr"`
+	AvailNum                   *byte   `xml:"availNum,attr"`
+	AvailsExpected             *byte   `xml:"availsExpected,attr"`
+
+	Program       *XMLInsertProgram    `xml:"Program"`
+	Components    []XMLInsertComponent `xml:"Component"`
+	BreakDuration *XMLBreakDuration    `xml:"BreakDuration"`
+}
+
+//XMLInsertProgram has no SpliceTime if splice_immediate_flag is set
+type XMLInsertProgram struct {
+	SpliceTime *XMLSpliceTime `xml:"SpliceTime"`
+}
+
+type XMLInsertComponent struct {
+	ComponentTag byte           `xml:"componentTag,attr"`
+	SpliceTime   *XMLSpliceTime `xml:"SpliceTime"`
+}
+
+type XMLTimeSignal struct {
+	SpliceTime *XMLSpliceTime `xml:"SpliceTime"`
+}
+
+//XMLSpliceTime has no ptsTime if time_specified_flag is not set
+type XMLSpliceTime struct {
+	PTSTime *uint64 `xml:"ptsTime,attr"`
+}
+
+type XMLBreakDuration struct {
+	AutoReturn bool   `xml:"autoReturn,attr"`
+	Duration   uint64 `xml:"duration,attr"`
+}
+
+//XMLPrivateCommand holds private_byte in hex
+type XMLPrivateCommand struct {
+	Identifier  uint32 `xml:"identifier,attr"`
+	PrivateByte string `xml:",chardata"`
+}
+
+//XMLSpliceDescriptor is any of AvailDescriptor, DTMFDescriptor, SegmentationDescriptor, TimeDescriptor and AudioDescriptor,
+//only the attributes and elements of the descriptor named by XMLName are used
+type XMLSpliceDescriptor struct {
+	XMLName xml.Name
+
+	//AvailDescriptor
+	ProviderAvailID *uint32 `xml:"providerAvailId,attr"`
+
+	//DTMFDescriptor
+	Preroll *byte   `xml:"preroll,attr"`
+	Chars   *string `xml:"chars,attr"`
+
+	//SegmentationDescriptor
+	SegmentationEventID                    *uint32                    `xml:"segmentationEventId,attr"`
+	SegmentationEventCancelIndicator       *bool                      `xml:"segmentationEventCancelIndicator,attr"`
+	SegmentationEventIDComplianceIndicator *bool                      `xml:"segmentationEventIdComplianceIndicator,attr"` //added since SCTE35 2019
+	SegmentationDuration                   *uint64                    `xml:"segmentationDuration,attr"`
+	SegmentationTypeID                     *uint8                     `xml:"segmentationTypeId,attr"`
+	SegmentNum                             *uint8                     `xml:"segmentNum,attr"`
+	SegmentsExpected                       *uint8                     `xml:"segmentsExpected,attr"`
+	SubSegmentNum                          *uint8                     `xml:"subSegmentNum,attr"`       //added since SCTE35 2016
+	SubSegmentsExpected                    *uint8                     `xml:"subSegmentsExpected,attr"` //added since SCTE35 2016
+	DeliveryRestrictions                   *XMLDeliveryRestrictions   `xml:"DeliveryRestrictions"`
+	SegmentationUpids                      []XMLSegmentationUpid      `xml:"SegmentationUpid"` //more than one for MID(0x0D)
+	Components                             []XMLSegmentationComponent `xml:"Component"`
+
+	//TimeDescriptor
+	TAISeconds *uint64 `xml:"taiSeconds,attr"`
+	TAINs      *uint32 `xml:"taiNs,attr"`
+	UTCOffset  *uint16 `xml:"utcOffset,attr"`
+
+	//AudioDescriptor
+	AudioChannels []XMLAudioChannel `xml:"AudioChannel"`
+}
+
+type XMLDeliveryRestrictions struct {
+	WebDeliveryAllowedFlag bool  `xml:"webDeliveryAllowedFlag,attr"`
+	NoRegionalBlackoutFlag bool  `xml:"noRegionalBlackoutFlag,attr"`
+	ArchiveAllowedFlag     bool  `xml:"archiveAllowedFlag,attr"`
+	DeviceRestrictions     uint8 `xml:"deviceRestrictions,attr"`
+}
+
+//XMLSegmentationUpid holds segmentation_upid in the format given by SegmentationUpidFormat, hexbinary if absent
+type XMLSegmentationUpid struct {
+	SegmentationUpidType   uint8   `xml:"segmentationUpidType,attr"`
+	SegmentationUpidFormat string  `xml:"segmentationUpidFormat,attr,omitempty"` //text, hexbinary or base-64
+	FormatIdentifier       *uint32 `xml:"formatIdentifier,attr"`                 //format_identifier of MPU(0x0C), if it is not part of the value
+	Value                  string  `xml:",chardata"`
+}
+
+type XMLSegmentationComponent struct {
+	ComponentTag byte   `xml:"componentTag,attr"`
+	PTSOffset    uint64 `xml:"ptsOffset,attr"`
+}
+
+type XMLAudioChannel struct {
+	ComponentTag  byte   `xml:"componentTag,attr"`
+	ISOCode       string `xml:"ISOCode,attr"`
+	BitStreamMode uint8  `xml:"BitStreamMode,attr"`
+	NumChannels   uint8  `xml:"NumChannels,attr"`
+	FullSrvcAudio bool   `xml:"FullSrvcAudio,attr"`
+}
+
+//XMLString serializes the XML element v, with indent if given
+func XMLString(v interface{}, indent ...string) (result string, err error) {
+	var buf []byte
+	if len(indent) == 0 {
+		buf, err = xml.Marshal(v)
+	} else {
+		buf, err = xml.MarshalIndent(v, "", indent[0])
+	}
+	return string(buf), err
+}
+
+//ParseXMLSpliceInfoSection parses a SpliceInfoSection element
+func ParseXMLSpliceInfoSection(xmlStr string) (section *XMLSpliceInfoSection, err error) {
+	section = &XMLSpliceInfoSection{}
+	if err = xml.Unmarshal([]byte(xmlStr), section); err != nil {
+		return nil, errors.New("Parse Error: " + err.Error())
+	}
+	return section, nil
+}
+
+//EncodeToXML returns the SpliceInfoSection of the header fields, the splice command and the splice descriptors are added by the schemas
+func (scte35 *SCTE35) EncodeToXML() *XMLSpliceInfoSection {
+	section := &XMLSpliceInfoSection{
+		Xmlns:           XMLNamespace,
+		PTSAdjustment:   scte35.PTSAdjustment,
+		ProtocolVersion: scte35.ProtocolVersion,
+		Tier:            scte35.Tier,
+	}
+	if scte35.EncryptedPacket {
+		section.EncryptedPacket = &XMLEncryptedPacket{EncryptionAlgorithm: scte35.EncryptionAlgorithm, CWIndex: scte35.CWIndex}
+	}
+	return section
+}
+
+//DecodeFromXML sets the header fields from section, splice_command_type is set by the command element present
+func (scte35 *SCTE35) DecodeFromXML(section *XMLSpliceInfoSection) (err error) {
+	scte35.TableID = 0xFC
+	scte35.SectionSyntaxIndicator = false
+	scte35.PrivateIndicator = false
+	scte35.ProtocolVersion = section.ProtocolVersion
+	scte35.PTSAdjustment = section.PTSAdjustment
+	scte35.Tier = section.Tier
+	scte35.EncryptedPacket = section.EncryptedPacket != nil
+	scte35.EncryptionAlgorithm, scte35.CWIndex = 0, 0
+	if section.EncryptedPacket != nil {
+		scte35.EncryptionAlgorithm = section.EncryptedPacket.EncryptionAlgorithm
+		scte35.CWIndex = section.EncryptedPacket.CWIndex
+	}
+	scte35.AlignmentStuffingInHex = nil
+	scte35.ECRC32InHex = nil
+
+	var commandTypes []SpliceCommandType
+	if section.SpliceNull != nil {
+		commandTypes = append(commandTypes, 0x00)
+	}
+	if section.SpliceSchedule != nil {
+		commandTypes = append(commandTypes, 0x04)
+	}
+	if section.SpliceInsert != nil {
+		commandTypes = append(commandTypes, 0x05)
+	}
+	if section.TimeSignal != nil {
+		commandTypes = append(commandTypes, 0x06)
+	}
+	if section.BandwidthReservation != nil {
+		commandTypes = append(commandTypes, 0x07)
+	}
+	if section.PrivateCommand != nil {
+		commandTypes = append(commandTypes, 0xff)
+	}
+	if len(commandTypes) != 1 {
+		return errors.New("Parse Error: SpliceInfoSection must have exactly one splice command, got " + strconv.Itoa(len(commandTypes)))
+	}
+	scte35.SpliceCommandType = commandTypes[0]
+	return nil
+}
+
+//EncodeToXML converts SpliceSchedule object to its XML element
+func (spliceSchedule *SpliceSchedule) EncodeToXML() *XMLSpliceSchedule {
+	x := &XMLSpliceSchedule{}
+	if spliceSchedule.ScheduleEvents == nil {
+		return x
+	}
+	for _, scheduleEvent := range *spliceSchedule.ScheduleEvents {
+		event := XMLScheduleEvent{
+			SpliceEventID:              scheduleEvent.SpliceEventID,
+			SpliceEventCancelIndicator: scheduleEvent.SpliceEventCancelIndicator,
+		}
+		if !scheduleEvent.SpliceEventCancelIndicator {
+			event.OutOfNetworkIndicator = scheduleEvent.OutOfNetworkIndicator
+			event.UniqueProgramID = scheduleEvent.UniqueProgramID
+			event.AvailNum = scheduleEvent.AvailNum
+			event.AvailsExpected = scheduleEvent.AvailsExpected
+			if scheduleEvent.ProgramSpliceFlag != nil && *scheduleEvent.ProgramSpliceFlag && scheduleEvent.UTCSpliceTime != nil {
+				event.Program = &XMLScheduleProgram{UTCSpliceTime: *scheduleEvent.UTCSpliceTime}
+			}
+			if scheduleEvent.ProgramSpliceFlag != nil && !*scheduleEvent.ProgramSpliceFlag && scheduleEvent.ScheduleComponents != nil {
+				for _, scheduleComp := range *scheduleEvent.ScheduleComponents {
+					event.Components = append(event.Components, XMLScheduleComponent{ComponentTag: scheduleComp.ComponentTag, UTCSpliceTime: scheduleComp.UTCSpliceTime})
+				}
+			}
+			if scheduleEvent.DurationFlag != nil && *scheduleEvent.DurationFlag && scheduleEvent.BreakDuration != nil {
+				event.BreakDuration = &XMLBreakDuration{AutoReturn: scheduleEvent.BreakDuration.AutoReturn, Duration: scheduleEvent.BreakDuration.Duration}
+			}
+		}
+		x.Events = append(x.Events, event)
+	}
+	return x
+}
+
+//DecodeFromXML sets SpliceSchedule object from its XML element
+func (spliceSchedule *SpliceSchedule) DecodeFromXML(x *XMLSpliceSchedule) (err error) {
+	scheduleEvents := []ScheduleEvent{}
+	for i, event := range x.Events {
+		scheduleEvent := ScheduleEvent{
+			SpliceEventID:              event.SpliceEventID,
+			SpliceEventCancelIndicator: event.SpliceEventCancelIndicator,
+		}
+		if !event.SpliceEventCancelIndicator {
+			path := "SpliceSchedule.Event[" + strconv.Itoa(i) + "]"
+			if event.OutOfNetworkIndicator == nil {
+				return missingXMLError(path, "outOfNetworkIndicator")
+			}
+			programSpliceFlag := event.Program != nil
+			durationFlag := event.BreakDuration != nil
+			scheduleEvent.OutOfNetworkIndicator = event.OutOfNetworkIndicator
+			scheduleEvent.ProgramSpliceFlag = &programSpliceFlag
+			scheduleEvent.DurationFlag = &durationFlag
+			if programSpliceFlag {
+				utcSpliceTime := event.Program.UTCSpliceTime
+				scheduleEvent.UTCSpliceTime = &utcSpliceTime
+			} else {
+				scheduleComponents := []ScheduleComponent{}
+				for _, comp := range event.Components {
+					scheduleComponents = append(scheduleComponents, ScheduleComponent{ComponentTag: comp.ComponentTag, UTCSpliceTime: comp.UTCSpliceTime})
+				}
+				scheduleEvent.ScheduleComponents = &scheduleComponents
+			}
+			if durationFlag {
+				scheduleEvent.BreakDuration = &BreakDuration{AutoReturn: event.BreakDuration.AutoReturn, Duration: event.BreakDuration.Duration}
+			}
+			scheduleEvent.UniqueProgramID = valueOrZero16(event.UniqueProgramID)
+			scheduleEvent.AvailNum = valueOrZero8(event.AvailNum)
+			scheduleEvent.AvailsExpected = valueOrZero8(event.AvailsExpected)
+		}
+		scheduleEvents = append(scheduleEvents, scheduleEvent)
+	}
+	spliceSchedule.ScheduleEvents = &scheduleEvents
+	spliceSchedule.SpliceCount = uint8(len(scheduleEvents))
+	return nil
+}
+
+//EncodeToXML converts SpliceInsert object to its XML element
+func (spliceInsert *SpliceInsert) EncodeToXML() *XMLSpliceInsert {
+	x := &XMLSpliceInsert{
+		SpliceEventID:              spliceInsert.SpliceEventID,
+		SpliceEventCancelIndicator: spliceInsert.SpliceEventCancelIndicator,
+	}
+	if spliceInsert.SpliceEventCancelIndicator {
+		return x
+	}
+
+	x.OutOfNetworkIndicator = spliceInsert.OutOfNetworkIndicator
+	x.SpliceImmediateFlag = spliceInsert.SpliceImmediateFlag
+	x.UniqueProgramID = spliceInsert.UniqueProgramID
+	x.AvailNum = spliceInsert.AvailNum
+	x.AvailsExpected = spliceInsert.AvailsExpected
+
+	immediate := spliceInsert.SpliceImmediateFlag != nil && *spliceInsert.SpliceImmediateFlag
+	if spliceInsert.ProgramSpliceFlag != nil && *spliceInsert.ProgramSpliceFlag {
+		x.Program = &XMLInsertProgram{}
+		if !immediate {
+			x.Program.SpliceTime = spliceInsert.SpliceTime.EncodeToXML()
+		}
+	}
+	if spliceInsert.ProgramSpliceFlag != nil && !*spliceInsert.ProgramSpliceFlag && spliceInsert.InsertComponents != nil {
+		for _, insertComp := range *spliceInsert.InsertComponents {
+			comp := XMLInsertComponent{ComponentTag: insertComp.ComponentTag}
+			if !immediate {
+				comp.SpliceTime = insertComp.SpliceTime.EncodeToXML()
+			}
+			x.Components = append(x.Components, comp)
+		}
+	}
+	if spliceInsert.DurationFlag != nil && *spliceInsert.DurationFlag && spliceInsert.BreakDuration != nil {
+		x.BreakDuration = &XMLBreakDuration{AutoReturn: spliceInsert.BreakDuration.AutoReturn, Duration: spliceInsert.BreakDuration.Duration}
+	}
+	return x
+}
+
+//DecodeFromXML sets SpliceInsert object from its XML element
+func (spliceInsert *SpliceInsert) DecodeFromXML(x *XMLSpliceInsert) (err error) {
+	*spliceInsert = SpliceInsert{
+		SpliceEventID:              x.SpliceEventID,
+		SpliceEventCancelIndicator: x.SpliceEventCancelIndicator,
+	}
+	if x.SpliceEventCancelIndicator {
+		return nil
+	}
+
+	if x.OutOfNetworkIndicator == nil {
+		return missingXMLError("SpliceInsert", "outOfNetworkIndicator")
+	}
+	programSpliceFlag := x.Program != nil
+	durationFlag := x.BreakDuration != nil
+	spliceImmediateFlag := x.SpliceImmediateFlag != nil && *x.SpliceImmediateFlag
+	spliceInsert.OutOfNetworkIndicator = x.OutOfNetworkIndicator
+	spliceInsert.ProgramSpliceFlag = &programSpliceFlag
+	spliceInsert.DurationFlag = &durationFlag
+	spliceInsert.SpliceImmediateFlag = &spliceImmediateFlag
+
+	if programSpliceFlag && !spliceImmediateFlag {
+		spliceInsert.SpliceTime = spliceTimeFromXML(x.Program.SpliceTime)
+	}
+	if !programSpliceFlag {
+		insertComponents := []InsertComponent{}
+		for _, comp := range x.Components {
+			insertComp := InsertComponent{ComponentTag: comp.ComponentTag}
+			if !spliceImmediateFlag {
+				insertComp.SpliceTime = spliceTimeFromXML(comp.SpliceTime)
+			}
+			insertComponents = append(insertComponents, insertComp)
+		}
+		spliceInsert.InsertComponents = &insertComponents
+	}
+	if durationFlag {
+		spliceInsert.BreakDuration = &BreakDuration{AutoReturn: x.BreakDuration.AutoReturn, Duration: x.BreakDuration.Duration}
+	}
+	spliceInsert.UniqueProgramID = valueOrZero16(x.UniqueProgramID)
+	spliceInsert.AvailNum = valueOrZero8(x.AvailNum)
+	spliceInsert.AvailsExpected = valueOrZero8(x.AvailsExpected)
+	return nil
+}
+
+//EncodeToXML converts TimeSignal object to its XML element
+func (timeSignal *TimeSignal) EncodeToXML() *XMLTimeSignal {
+	return &XMLTimeSignal{SpliceTime: timeSignal.SpliceTime.EncodeToXML()}
+}
+
+//DecodeFromXML sets TimeSignal object from its XML element
+func (timeSignal *TimeSignal) DecodeFromXML(x *XMLTimeSignal) (err error) {
+	timeSignal.SpliceTime = spliceTimeFromXML(x.SpliceTime)
+	return nil
+}
+
+//EncodeToXML converts SpliceTime object to its XML element
+func (spliceTime *SpliceTime) EncodeToXML() *XMLSpliceTime {
+	x := &XMLSpliceTime{}
+	if spliceTime != nil && spliceTime.TimeSpecifiedFlag {
+		x.PTSTime = spliceTime.PTSTime
+	}
+	return x
+}
+
+func spliceTimeFromXML(x *XMLSpliceTime) *SpliceTime {
+	if x == nil || x.PTSTime == nil {
+		return &SpliceTime{TimeSpecifiedFlag: false}
+	}
+	ptsTime := *x.PTSTime
+	return &SpliceTime{TimeSpecifiedFlag: true, PTSTime: &ptsTime}
+}
+
+//EncodeToXML converts PrivateCommand object to its XML element
+func (privateCommand *PrivateCommand) EncodeToXML() *XMLPrivateCommand {
+	x := &XMLPrivateCommand{Identifier: privateCommand.Identifier}
+	if privateCommand.PrivateByteInHex != nil {
+		x.PrivateByte = *privateCommand.PrivateByteInHex
+	}
+	return x
+}
+
+//DecodeFromXML sets PrivateCommand object from its XML element
+func (privateCommand *PrivateCommand) DecodeFromXML(x *XMLPrivateCommand) (err error) {
+	privateCommand.Identifier = x.Identifier
+	privateCommand.PrivateByteInHex = nil
+	if privateByte := strings.TrimSpace(x.PrivateByte); privateByte != "" {
+		if _, err = hex.DecodeString(privateByte); err != nil {
+			return errors.New("Parse Error: PrivateCommand must be hex: " + err.Error())
+		}
+		privateCommand.PrivateByteInHex = &privateByte
+	}
+	return nil
+}
+
+//EncodeToXML converts AvailDescriptor object to its XML element
+func (availDesc *AvailDescriptor) EncodeToXML() *XMLSpliceDescriptor {
+	providerAvailID := availDesc.ProviderAvailID
+	return &XMLSpliceDescriptor{XMLName: xml.Name{Local: "AvailDescriptor"}, ProviderAvailID: &providerAvailID}
+}
+
+//DecodeFromXML sets AvailDescriptor object from its XML element
+func (availDesc *AvailDescriptor) DecodeFromXML(x *XMLSpliceDescriptor) (err error) {
+	if x.ProviderAvailID == nil {
+		return missingXMLError("AvailDescriptor", "providerAvailId")
+	}
+	availDesc.ProviderAvailID = *x.ProviderAvailID
+	return nil
+}
+
+//EncodeToXML converts DTMFDescriptor object to its XML element
+func (dtmfDesc *DTMFDescriptor) EncodeToXML() *XMLSpliceDescriptor {
+	preroll, chars := dtmfDesc.Preroll, dtmfDesc.DTMFChars
+	return &XMLSpliceDescriptor{XMLName: xml.Name{Local: "DTMFDescriptor"}, Preroll: &preroll, Chars: &chars}
+}
+
+//DecodeFromXML sets DTMFDescriptor object from its XML element
+func (dtmfDesc *DTMFDescriptor) DecodeFromXML(x *XMLSpliceDescriptor) (err error) {
+	if x.Preroll == nil {
+		return missingXMLError("DTMFDescriptor", "preroll")
+	}
+	dtmfDesc.Preroll = *x.Preroll
+	dtmfDesc.DTMFChars = ""
+	if x.Chars != nil {
+		dtmfDesc.DTMFChars = *x.Chars
+	}
+	dtmfDesc.DTMFCount = uint8(len(dtmfDesc.DTMFChars))
+	return nil
+}
+
+//EncodeToXML converts TimeDescriptor object to its XML element
+func (timeDesc *TimeDescriptor) EncodeToXML() *XMLSpliceDescriptor {
+	taiSeconds, taiNs, utcOffset := timeDesc.TAI_seconds, timeDesc.TAI_ns, timeDesc.UTC_offset
+	return &XMLSpliceDescriptor{XMLName: xml.Name{Local: "TimeDescriptor"}, TAISeconds: &taiSeconds, TAINs: &taiNs, UTCOffset: &utcOffset}
+}
+
+//DecodeFromXML sets TimeDescriptor object from its XML element
+func (timeDesc *TimeDescriptor) DecodeFromXML(x *XMLSpliceDescriptor) (err error) {
+	if x.TAISeconds == nil {
+		return missingXMLError("TimeDescriptor", "taiSeconds")
+	}
+	timeDesc.TAI_seconds = *x.TAISeconds
+	timeDesc.TAI_ns = 0
+	if x.TAINs != nil {
+		timeDesc.TAI_ns = *x.TAINs
+	}
+	timeDesc.UTC_offset = 0
+	if x.UTCOffset != nil {
+		timeDesc.UTC_offset = *x.UTCOffset
+	}
+	return nil
+}
+
+//EncodeToXML converts SegmentationDescriptor object to its XML element, sub_segment_num and sub_segments_expected are added by the schemas
+//segmentation_upid is written in hexbinary, the UPIDs of MID(0x0D) are written as separate SegmentationUpid elements
+func (segDesc *SegmentationDescriptor) EncodeToXML() *XMLSpliceDescriptor {
+	segmentationEventID, cancel := segDesc.SegmentationEventID, segDesc.SegmentationEventCancelIndicator
+	x := &XMLSpliceDescriptor{
+		XMLName:                          xml.Name{Local: "SegmentationDescriptor"},
+		SegmentationEventID:              &segmentationEventID,
+		SegmentationEventCancelIndicator: &cancel,
+	}
+	if cancel {
+		return x
+	}
+
+	if segDesc.SegmentationDurationFlag != nil && *segDesc.SegmentationDurationFlag {
+		x.SegmentationDuration = segDesc.SegmentationDuration
+	}
+	if segDesc.SegmentationTypeID != nil {
+		segmentationTypeID := uint8(*segDesc.SegmentationTypeID)
+		x.SegmentationTypeID = &segmentationTypeID
+	}
+	x.SegmentNum = segDesc.SegmentNum
+	x.SegmentsExpected = segDesc.SegmentsExpected
+
+	if segDesc.DeliveryNotRestrictedFlag != nil && !*segDesc.DeliveryNotRestrictedFlag {
+		restrictions := &XMLDeliveryRestrictions{}
+		if segDesc.WebDeliveryAllowedFlag != nil {
+			restrictions.WebDeliveryAllowedFlag = *segDesc.WebDeliveryAllowedFlag
+		}
+		if segDesc.NoRegionalBlackoutFlag != nil {
+			restrictions.NoRegionalBlackoutFlag = *segDesc.NoRegionalBlackoutFlag
+		}
+		if segDesc.ArchiveAllowedFlag != nil {
+			restrictions.ArchiveAllowedFlag = *segDesc.ArchiveAllowedFlag
+		}
+		if segDesc.DeviceRestrictions != nil {
+			restrictions.DeviceRestrictions = uint8(*segDesc.DeviceRestrictions)
+		}
+		x.DeliveryRestrictions = restrictions
+	}
+
+	if segDesc.SegmentationUpidType != nil {
+		upidType := uint8(*segDesc.SegmentationUpidType)
+		upidInHex := ""
+		if segDesc.SegmentationUpidInHex != nil {
+			upidInHex = *segDesc.SegmentationUpidInHex
+		}
+		mid := &MIDUpid{}
+		midBytes, err := hex.DecodeString(upidInHex)
+		if upidType == 0x0D && err == nil && len(midBytes) > 0 {
+			if _, err = mid.DecodeFromRawBytes(midBytes); err == nil {
+				for _, entry := range mid.Upids {
+					x.SegmentationUpids = append(x.SegmentationUpids, XMLSegmentationUpid{SegmentationUpidType: uint8(entry.SegmentationUpidType), SegmentationUpidFormat: "hexbinary", Value: strings.ToUpper(entry.SegmentationUpidInHex)})
+				}
+			}
+		}
+		if x.SegmentationUpids == nil {
+			x.SegmentationUpids = []XMLSegmentationUpid{{SegmentationUpidType: upidType, SegmentationUpidFormat: "hexbinary", Value: strings.ToUpper(upidInHex)}}
+		}
+	}
+
+	if segDesc.ProgramSegmentationFlag != nil && !*segDesc.ProgramSegmentationFlag && segDesc.SegmentationComponents != nil {
+		for _, segComp := range *segDesc.SegmentationComponents {
+			x.Components = append(x.Components, XMLSegmentationComponent{ComponentTag: segComp.ComponentTag, PTSOffset: segComp.PTSOffset})
+		}
+	}
+	return x
+}
+
+//DecodeFromXML sets SegmentationDescriptor object from its XML element
+func (segDesc *SegmentationDescriptor) DecodeFromXML(x *XMLSpliceDescriptor) (err error) {
+	if x.SegmentationEventID == nil {
+		return missingXMLError("SegmentationDescriptor", "segmentationEventId")
+	}
+	*segDesc = SegmentationDescriptor{SegmentationEventID: *x.SegmentationEventID}
+	segDesc.SegmentationEventCancelIndicator = x.SegmentationEventCancelIndicator != nil && *x.SegmentationEventCancelIndicator
+	if segDesc.SegmentationEventCancelIndicator {
+		return nil
+	}
+
+	if x.SegmentationTypeID == nil {
+		return missingXMLError("SegmentationDescriptor", "segmentationTypeId")
+	}
+	segmentationTypeID := SegmentationTypeID(*x.SegmentationTypeID)
+	segDesc.SegmentationTypeID = &segmentationTypeID
+	segDesc.SegmentNum = valueOrZero8(x.SegmentNum)
+	segDesc.SegmentsExpected = valueOrZero8(x.SegmentsExpected)
+
+	programSegmentationFlag := len(x.Components) == 0
+	segmentationDurationFlag := x.SegmentationDuration != nil
+	deliveryNotRestrictedFlag := x.DeliveryRestrictions == nil
+	segDesc.ProgramSegmentationFlag = &programSegmentationFlag
+	segDesc.SegmentationDurationFlag = &segmentationDurationFlag
+	segDesc.DeliveryNotRestrictedFlag = &deliveryNotRestrictedFlag
+
+	if segmentationDurationFlag {
+		segmentationDuration := *x.SegmentationDuration
+		segDesc.SegmentationDuration = &segmentationDuration
+	}
+	if !deliveryNotRestrictedFlag {
+		restrictions := x.DeliveryRestrictions
+		deviceRestrictions := DeviceRestrictions(restrictions.DeviceRestrictions & 0x03)
+		segDesc.WebDeliveryAllowedFlag = &restrictions.WebDeliveryAllowedFlag
+		segDesc.NoRegionalBlackoutFlag = &restrictions.NoRegionalBlackoutFlag
+		segDesc.ArchiveAllowedFlag = &restrictions.ArchiveAllowedFlag
+		segDesc.DeviceRestrictions = &deviceRestrictions
+	}
+	if !programSegmentationFlag {
+		components := []SegmentationComponent{}
+		for _, comp := range x.Components {
+			components = append(components, SegmentationComponent{ComponentTag: comp.ComponentTag, PTSOffset: comp.PTSOffset})
+		}
+		segDesc.SegmentationComponents = &components
+	}
+
+	upidType := SegmentationUpidType(0x00)
+	var upidBytes []byte
+	switch {
+	case len(x.SegmentationUpids) == 1 && x.SegmentationUpids[0].SegmentationUpidType != 0x0D:
+		upidType = SegmentationUpidType(x.SegmentationUpids[0].SegmentationUpidType)
+		if upidBytes, err = x.SegmentationUpids[0].bytes(); err != nil {
+			return err
+		}
+	case len(x.SegmentationUpids) == 1:
+		//a MID given as a single value
+		upidType = 0x0D
+		if upidBytes, err = x.SegmentationUpids[0].bytes(); err != nil {
+			return err
+		}
+	case len(x.SegmentationUpids) > 1:
+		upidType = 0x0D
+		for _, upid := range x.SegmentationUpids {
+			entryBytes, err := upid.bytes()
+			if err != nil {
+				return err
+			}
+			if len(entryBytes) > 0xff {
+				return errors.New("Parse Error: SegmentationUpid of MID is longer than 255 bytes")
+			}
+			upidBytes = append(upidBytes, upid.SegmentationUpidType, uint8(len(entryBytes)))
+			upidBytes = append(upidBytes, entryBytes...)
+		}
+	}
+	upidInHex := hex.EncodeToString(upidBytes)
+	upidLength := uint8(len(upidBytes))
+	segDesc.SegmentationUpidType = &upidType
+	segDesc.SegmentationUpidLength = &upidLength
+	segDesc.SegmentationUpidInHex = &upidInHex
+	segDesc.DecodeSegmentationUpid()
+	return nil
+}
+
+//bytes returns the value of SegmentationUpid according to segmentationUpidFormat
+func (upid *XMLSegmentationUpid) bytes() (output []byte, err error) {
+	value := strings.TrimSpace(upid.Value)
+	switch strings.ToLower(upid.SegmentationUpidFormat) {
+	case "", "hexbinary":
+		output, err = hex.DecodeString(value)
+	case "text":
+		output = []byte(upid.Value)
+	case "base-64", "base64":
+		output, err = base64.StdEncoding.DecodeString(value)
+	default:
+		return nil, errors.New("Parse Error: unsupported segmentationUpidFormat: " + upid.SegmentationUpidFormat)
+	}
+	if err != nil {
+		return nil, errors.New("Parse Error: SegmentationUpid is not valid " + upid.SegmentationUpidFormat + ": " + err.Error())
+	}
+	if upid.FormatIdentifier != nil {
+		formatIdentifier := make([]byte, 4)
+		binary.BigEndian.PutUint32(formatIdentifier, *upid.FormatIdentifier)
+		output = append(formatIdentifier, output...)
+	}
+	return output, nil
+}
+
+func missingXMLError(element string, attribute string) error {
+	return errors.New("Parse Error: " + element + " requires attribute " + attribute)
+}
+
+func valueOrZero8(value *uint8) *uint8 {
+	v := uint8(0)
+	if value != nil {
+		v = *value
+	}
+	return &v
+}
+
+func valueOrZero16(value *uint16) *uint16 {
+	v := uint16(0)
+	if value != nil {
+		v = *value
+	}
+	return &v
+}
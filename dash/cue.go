@@ -0,0 +1,144 @@
+package dash
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+)
+
+//Cue is the splice_info_section of an Event, with the times converted by the timescale of EventStream
+type Cue struct {
+	ID *uint32 `json:"id,omitempty"`
+	//PresentationTime is (presentationTime - presentationTimeOffset) / timescale, i.e. relative to the start of the Period
+	PresentationTime time.Duration  `json:"presentation_time"`
+	Duration         *time.Duration `json:"duration,omitempty"`
+
+	Payload []byte        `json:"-"`
+	Parser  common.Parser `json:"scte35,omitempty"`
+	Err     error         `json:"-"` //error of decoding Payload
+}
+
+//NewEventStream returns an empty EventStream of scheme, timescale is the ticks per second of the times of its events
+func NewEventStream(schemeIDURI string, timescale uint64) *EventStream {
+	return &EventStream{SchemeIDURI: schemeIDURI, Timescale: &timescale}
+}
+
+//timescale returns the timescale of EventStream, 1 if absent as defined by ISO/IEC 23009-1
+func (eventStream *EventStream) timescale() uint64 {
+	if eventStream.Timescale == nil || *eventStream.Timescale == 0 {
+		return 1
+	}
+	return *eventStream.Timescale
+}
+
+//ToDuration converts ticks of the timescale of EventStream to time.Duration
+func (eventStream *EventStream) ToDuration(ticks uint64) time.Duration {
+	timescale := eventStream.timescale()
+	return time.Duration(ticks/timescale)*time.Second + time.Duration(ticks%timescale)*time.Second/time.Duration(timescale)
+}
+
+//ToTicks converts time.Duration to ticks of the timescale of EventStream, negative duration is treated as 0
+func (eventStream *EventStream) ToTicks(d time.Duration) uint64 {
+	if d < 0 {
+		return 0
+	}
+	timescale := eventStream.timescale()
+	return uint64(d/time.Second)*timescale + uint64(d%time.Second)*timescale/uint64(time.Second)
+}
+
+//Payload returns the splice_info_section of Event, which is the base64 of Signal/Binary, messageData or the content of Event
+func (event *Event) Payload() ([]byte, error) {
+	encoded := ""
+	switch {
+	case event.Signal != nil && len(event.Signal.Binary) > 0:
+		encoded = event.Signal.Binary[0]
+	case event.MessageData != "":
+		encoded = event.MessageData
+	default:
+		encoded = event.Content
+	}
+	encoded = strings.Join(strings.Fields(encoded), "")
+	if encoded == "" {
+		return nil, errors.New("Parse Error: Event has no Signal/Binary")
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+//Cues decodes the splice_info_section of every Event
+func (eventStream *EventStream) Cues() []*Cue {
+	return eventStream.CuesWithOptions(common.DecodeOptions{})
+}
+
+//CuesWithOptions is Cues with the splice_info_sections decoded by opts, the error of an Event is reported by Cue.Err
+func (eventStream *EventStream) CuesWithOptions(opts common.DecodeOptions) (cues []*Cue) {
+	var offset uint64
+	if eventStream.PresentationTimeOffset != nil {
+		offset = *eventStream.PresentationTimeOffset
+	}
+
+	for i, event := range eventStream.Events {
+		cue := &Cue{ID: event.ID}
+		if event.PresentationTime != nil {
+			if *event.PresentationTime >= offset {
+				cue.PresentationTime = eventStream.ToDuration(*event.PresentationTime - offset)
+			} else {
+				cue.PresentationTime = -eventStream.ToDuration(offset - *event.PresentationTime)
+			}
+		}
+		if event.Duration != nil {
+			duration := eventStream.ToDuration(*event.Duration)
+			cue.Duration = &duration
+		}
+
+		cue.Payload, cue.Err = event.Payload()
+		if cue.Err != nil {
+			cue.Err = errors.New("Parse Error: Event[" + strconv.Itoa(i) + "]: " + cue.Err.Error())
+		} else {
+			cue.Parser, cue.Err = decoder.DecodeWithOptions(cue.Payload, opts)
+		}
+		cues = append(cues, cue)
+	}
+	return cues
+}
+
+//AddCue appends an Event carrying parser in Signal/Binary, presentationTime is relative to the start of the Period
+func (eventStream *EventStream) AddCue(parser common.Parser, presentationTime time.Duration, duration *time.Duration, id uint32) error {
+	payload, err := parser.EncodeToRawBytes()
+	if err != nil {
+		return err
+	}
+
+	presentationTicks := eventStream.ToTicks(presentationTime)
+	if eventStream.PresentationTimeOffset != nil {
+		presentationTicks += *eventStream.PresentationTimeOffset
+	}
+	event := &Event{
+		PresentationTime: &presentationTicks,
+		ID:               &id,
+		Signal:           &Signal{Xmlns: SignalNamespace, Binary: []string{base64.StdEncoding.EncodeToString(payload)}},
+	}
+	if duration != nil {
+		durationTicks := eventStream.ToTicks(*duration)
+		event.Duration = &durationTicks
+	}
+	eventStream.Events = append(eventStream.Events, event)
+	return nil
+}
+
+//XML serializes EventStream object to an element which can be put in a Period
+func (eventStream *EventStream) XML(indent ...string) (string, error) {
+	var output []byte
+	var err error
+	if len(indent) > 0 {
+		output, err = xml.MarshalIndent(eventStream, "", indent[0])
+	} else {
+		output, err = xml.Marshal(eventStream)
+	}
+	return string(output), err
+}
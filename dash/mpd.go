@@ -0,0 +1,139 @@
+package dash
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+//Schemes of SCTE 35 event streams, see SCTE 214-1
+const (
+	//SchemeSCTE35Bin carries the splice_info_section in base64, in Signal/Binary or as the content of Event
+	SchemeSCTE35Bin = "urn:scte:scte35:2013:bin"
+	//SchemeSCTE35XMLBin carries the splice_info_section in base64 in Signal/Binary
+	SchemeSCTE35XMLBin = "urn:scte:scte35:2014:xml+bin"
+)
+
+//SignalNamespace is the namespace of Signal written by EventStream
+const SignalNamespace = "http://www.scte.org/schemas/35/2016"
+
+//EventStream is an EventStream element of a Period, see ISO/IEC 23009-1 5.10.2
+type EventStream struct {
+	XMLName                xml.Name `xml:"EventStream"`
+	SchemeIDURI            string   `xml:"schemeIdUri,attr"`
+	Value                  string   `xml:"value,attr,omitempty"`
+	Timescale              *uint64  `xml:"timescale,attr"`
+	PresentationTimeOffset *uint64  `xml:"presentationTimeOffset,attr"`
+	Events                 []*Event `xml:"Event"`
+
+	PeriodID    string `xml:"-"` //id of the enclosing Period, set by ReadEventStreams
+	PeriodStart string `xml:"-"` //start of the enclosing Period(xs:duration), set by ReadEventStreams
+}
+
+//Event is an Event element of EventStream
+type Event struct {
+	PresentationTime *uint64 `xml:"presentationTime,attr"` //in the timescale of EventStream, 0 if absent
+	Duration         *uint64 `xml:"duration,attr"`
+	ID               *uint32 `xml:"id,attr"`
+	MessageData      string  `xml:"messageData,attr,omitempty"`
+
+	Signal  *Signal `xml:"Signal"`
+	Content string  `xml:",chardata"`
+}
+
+//Signal is the Signal element of SCTE 35 XML, only its Binary is supported
+type Signal struct {
+	Xmlns  string   `xml:"xmlns,attr,omitempty"` //empty if Signal is read with a prefix, e.g. scte35:Signal
+	Binary []string `xml:"Binary"`
+}
+
+//MarshalXML writes Signal in SignalNamespace if Xmlns is empty, so that Signal and Binary do not fall into the namespace of the MPD
+func (signal Signal) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plainSignal Signal
+	if signal.Xmlns == "" {
+		signal.Xmlns = SignalNamespace
+	}
+	return e.EncodeElement(plainSignal(signal), start)
+}
+
+//InbandEventStream announces that the segments of an AdaptationSet or a Representation carry the events in emsg boxes
+type InbandEventStream struct {
+	XMLName     xml.Name `xml:"InbandEventStream"`
+	SchemeIDURI string   `xml:"schemeIdUri,attr"`
+	Value       string   `xml:"value,attr,omitempty"`
+
+	PeriodID    string `xml:"-"`
+	PeriodStart string `xml:"-"`
+}
+
+//IsSCTE35Scheme reports whether schemeIDURI is one of the SCTE 35 schemes supported
+func IsSCTE35Scheme(schemeIDURI string) bool {
+	switch strings.TrimSpace(schemeIDURI) {
+	case SchemeSCTE35Bin, SchemeSCTE35XMLBin:
+		return true
+	}
+	return false
+}
+
+//ReadEventStreams reads an MPD and returns its EventStream and InbandEventStream elements of the SCTE 35 schemes
+//The elements are found anywhere in the document, together with the id and start of their Period
+func ReadEventStreams(reader io.Reader) (eventStreams []*EventStream, inbandEventStreams []*InbandEventStream, err error) {
+	d := xml.NewDecoder(reader)
+	periodID, periodStart := "", ""
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return eventStreams, inbandEventStreams, nil
+		}
+		if err != nil {
+			return eventStreams, inbandEventStreams, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "Period":
+			periodID, periodStart = attr(start, "id"), attr(start, "start")
+		case "EventStream":
+			eventStream := &EventStream{}
+			if err = d.DecodeElement(eventStream, &start); err != nil {
+				return eventStreams, inbandEventStreams, err
+			}
+			if IsSCTE35Scheme(eventStream.SchemeIDURI) {
+				eventStream.PeriodID, eventStream.PeriodStart = periodID, periodStart
+				eventStreams = append(eventStreams, eventStream)
+			}
+		case "InbandEventStream":
+			inbandEventStream := &InbandEventStream{}
+			if err = d.DecodeElement(inbandEventStream, &start); err != nil {
+				return eventStreams, inbandEventStreams, err
+			}
+			if IsSCTE35Scheme(inbandEventStream.SchemeIDURI) {
+				inbandEventStream.PeriodID, inbandEventStream.PeriodStart = periodID, periodStart
+				inbandEventStreams = append(inbandEventStreams, inbandEventStream)
+			}
+		}
+	}
+}
+
+//NewInbandEventStream returns an InbandEventStream of scheme
+func NewInbandEventStream(schemeIDURI string) *InbandEventStream {
+	return &InbandEventStream{SchemeIDURI: schemeIDURI}
+}
+
+//XML serializes InbandEventStream object to an element
+func (inbandEventStream *InbandEventStream) XML() (string, error) {
+	output, err := xml.Marshal(inbandEventStream)
+	return string(output), err
+}
+
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
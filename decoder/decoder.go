@@ -0,0 +1,197 @@
+package decoder
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	schema_2013 "github.com/chanyk-joseph/scte35_decoder/2013"
+	schema_2017 "github.com/chanyk-joseph/scte35_decoder/2017"
+	schema_2022 "github.com/chanyk-joseph/scte35_decoder/2022"
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//SchemaParser is a common.Parser which can be used by the version-agnostic Decode
+type SchemaParser interface {
+	common.Parser
+	DecodeFromRawBytesWithOptions([]byte, common.DecodeOptions) (int, error)
+	EncodeToRawBytesWithOptions(common.EncodeOptions) ([]byte, error)
+	JSONWithNames(...string) string
+	XML(...string) string
+	DecodeFromXML(string) error
+	Validate() []common.Finding
+	CRC32Mismatch() *common.CRC32MismatchError
+	Problems() common.DecodeErrors
+	Fields() []common.Field
+}
+
+//Detection describes which schema is chosen by Detect and why
+type Detection struct {
+	SchemaVersion string `json:"schema_version"`
+	Reason        string `json:"reason"`
+}
+
+type candidate struct {
+	newParser func() SchemaParser
+	//evidence returns a non-empty reason if the decoded section uses fields which only exist in this schema
+	evidence func(SchemaParser) string
+}
+
+//candidates are ordered from the latest schema to the oldest one
+var candidates = []candidate{
+	{
+		newParser: func() SchemaParser { return &schema_2022.SCTE35{} },
+		evidence:  evidenceOf2022,
+	},
+	{
+		newParser: func() SchemaParser { return &schema_2017.SCTE35{} },
+		evidence:  evidenceOf2017,
+	},
+	{
+		newParser: func() SchemaParser { return &schema_2013.SCTE35{} },
+		evidence:  func(SchemaParser) string { return "" },
+	},
+}
+
+//the compliance flags are not used as evidence, since legacy encoders often write reserved bits as 0
+func evidenceOf2022(parser SchemaParser) string {
+	scte35 := parser.(*schema_2022.SCTE35)
+	for _, spliceDesc := range scte35.SpliceDescriptors {
+		if spliceDesc.AudioDescriptor != nil {
+			return "audio_descriptor(0x04) is present"
+		}
+		segDesc := spliceDesc.SegmentationDescriptor
+		if segDesc == nil {
+			continue
+		}
+		if segDesc.SubSegmentNum != nil && *segDesc.SegmentationTypeID != 0x34 && *segDesc.SegmentationTypeID != 0x36 {
+			return "sub_segment_num and sub_segments_expected are present for segmentation_type_id " + "0x" + strconv.FormatUint(uint64(*segDesc.SegmentationTypeID), 16)
+		}
+	}
+	return ""
+}
+
+func evidenceOf2017(parser SchemaParser) string {
+	scte35 := parser.(*schema_2017.SCTE35)
+	for _, spliceDesc := range scte35.SpliceDescriptors {
+		if spliceDesc.TimeDescriptor != nil {
+			return "time_descriptor(0x03) is present"
+		}
+		if spliceDesc.SegmentationDescriptor != nil && spliceDesc.SegmentationDescriptor.SubSegmentNum != nil {
+			return "sub_segment_num and sub_segments_expected are present for segmentation_type_id 0x34/0x36"
+		}
+	}
+	return ""
+}
+
+//NewParser returns an empty parser of schemaVersion, e.g. "v2017"(the leading "v" is optional)
+func NewParser(schemaVersion string) (SchemaParser, error) {
+	version := strings.ToLower(strings.TrimSpace(schemaVersion))
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	for _, c := range candidates {
+		if parser := c.newParser(); parser.SchemaVersion() == version {
+			return parser, nil
+		}
+	}
+	return nil, errors.New("Unknown Schema Version: " + schemaVersion)
+}
+
+//Decode parses input with the most appropriate schema, see Detect for how the schema is chosen
+//If CRC_32 does not match, the decoded parser is returned together with *common.CRC32MismatchError
+func Decode(input []byte) (common.Parser, error) {
+	return DecodeWithOptions(input, common.DecodeOptions{})
+}
+
+//DecodeWithOptions is Decode with the behaviour controlled by opts
+//With opts.Lenient, the partial result is returned together with common.DecodeErrors if input cannot be decoded strictly by any schema
+func DecodeWithOptions(input []byte, opts common.DecodeOptions) (common.Parser, error) {
+	parser, _, err := detect(input, opts)
+	return parser, err
+}
+
+//Detect reports the schema Decode would use for input
+//The latest schema whose specific fields(e.g. time_descriptor 0x03 for 2017, audio_descriptor 0x04 for 2022) are found is chosen,
+//otherwise the latest schema which is able to decode input is chosen
+func Detect(input []byte) (Detection, error) {
+	_, detection, err := detect(input, common.DecodeOptions{})
+	return detection, err
+}
+
+func detect(input []byte, opts common.DecodeOptions) (SchemaParser, Detection, error) {
+	var fallback SchemaParser
+	var fallbackCandidate candidate
+	var fallbackReason string
+	var errMsgs []string
+
+	decodeOpts := opts
+	decodeOpts.IgnoreCRC32Mismatch = true
+	decodeOpts.Lenient = false //the schema is detected by strict decoding, lenient decoding is only used if no schema is able to decode input
+	for _, c := range candidates {
+		parser := c.newParser()
+		if _, err := parser.DecodeFromRawBytesWithOptions(input, decodeOpts); err != nil {
+			errMsgs = append(errMsgs, parser.SchemaVersion()+": "+err.Error())
+			continue
+		}
+
+		if reason := c.evidence(parser); reason != "" {
+			return result(input, c, parser, reason, opts)
+		}
+		if fallback == nil {
+			fallback, fallbackCandidate = parser, c
+			if len(errMsgs) == 0 {
+				fallbackReason = "no schema specific field is found, the latest schema is used"
+			} else {
+				fallbackReason = "unable to decode with newer schema(" + strings.Join(errMsgs, "; ") + ")"
+			}
+		}
+	}
+
+	if fallback == nil && opts.Lenient {
+		return detectLeniently(input, opts)
+	}
+	if fallback == nil {
+		return nil, Detection{}, errors.New("Unable To Decode With Any Schema: " + strings.Join(errMsgs, "; "))
+	}
+	return result(input, fallbackCandidate, fallback, fallbackReason, opts)
+}
+
+//detectLeniently decodes input leniently with every schema and picks the latest one with the fewest problems
+func detectLeniently(input []byte, opts common.DecodeOptions) (SchemaParser, Detection, error) {
+	var best SchemaParser
+	var bestProblems common.DecodeErrors
+	for _, c := range candidates {
+		parser := c.newParser()
+		_, err := parser.DecodeFromRawBytesWithOptions(input, opts)
+		problems, _ := err.(common.DecodeErrors)
+		if best == nil || len(problems) < len(bestProblems) {
+			best, bestProblems = parser, problems
+		}
+	}
+
+	reason := "no schema is able to decode input strictly, the schema with the fewest problems(" + strconv.Itoa(len(bestProblems)) + ") is used"
+	detection := Detection{SchemaVersion: best.SchemaVersion(), Reason: reason}
+	if len(bestProblems) == 0 {
+		return best, detection, nil
+	}
+	return best, detection, bestProblems
+}
+
+//result returns parser decoded strictly by c, with opts.Lenient input is decoded again by a new parser of c
+func result(input []byte, c candidate, parser SchemaParser, reason string, opts common.DecodeOptions) (SchemaParser, Detection, error) {
+	detection := Detection{SchemaVersion: parser.SchemaVersion(), Reason: reason}
+	if opts.Lenient {
+		//decode again, so that CRC_32 mismatch is reported by Problems() as well
+		//a new parser is used, since decoding appends the splice descriptors to those already decoded
+		parser = c.newParser()
+		if _, err := parser.DecodeFromRawBytesWithOptions(input, opts); err != nil {
+			return parser, detection, err
+		}
+		return parser, detection, nil
+	}
+	if mismatch := parser.CRC32Mismatch(); mismatch != nil && !opts.IgnoreCRC32Mismatch {
+		return parser, detection, mismatch
+	}
+	return parser, detection, nil
+}
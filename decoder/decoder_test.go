@@ -0,0 +1,30 @@
+package decoder_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	schema_2022 "github.com/chanyk-joseph/scte35_decoder/2022"
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+)
+
+//readmeCue is the time_signal of the README with a segmentation_descriptor and an avail_descriptor
+const readmeCue = "fc304700000000000000fff00506fe1909d1f9002f0223435545490000000a7f9f01144e6174696f6e616c5f4261636b4f75745f456e64310000f0085053394b546524dd8c7fef2b10a4"
+
+func TestDecodeLenientDescriptorCount(t *testing.T) {
+	input, _ := hex.DecodeString(readmeCue)
+	for _, opts := range []common.DecodeOptions{{}, {Lenient: true}} {
+		parser, err := decoder.DecodeWithOptions(input, opts)
+		if err != nil {
+			t.Fatalf("lenient %v: unable to decode: %v", opts.Lenient, err)
+		}
+		scte35, ok := parser.(*schema_2022.SCTE35)
+		if !ok {
+			t.Fatalf("lenient %v: decoded by schema %s", opts.Lenient, parser.SchemaVersion())
+		}
+		if len(scte35.SpliceDescriptors) != 2 {
+			t.Errorf("lenient %v: %d splice descriptors, expected 2", opts.Lenient, len(scte35.SpliceDescriptors))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package hls
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//parseAttributes parses an attribute-list of RFC 8216 4.2, quoted-string values are unquoted
+func parseAttributes(list string) (attributes map[string]string, err error) {
+	attributes = map[string]string{}
+	for len(list) > 0 {
+		eq := strings.IndexByte(list, '=')
+		if eq <= 0 {
+			return attributes, errors.New("Parse Error: attribute without value: " + list)
+		}
+		name := strings.TrimSpace(list[:eq])
+		list = list[eq+1:]
+
+		var value string
+		if strings.HasPrefix(list, "\"") {
+			end := strings.IndexByte(list[1:], '"')
+			if end < 0 {
+				return attributes, errors.New("Parse Error: unterminated quoted-string of attribute " + name)
+			}
+			value = list[1 : end+1]
+			list = list[end+2:]
+		} else {
+			end := strings.IndexByte(list, ',')
+			if end < 0 {
+				end = len(list)
+			}
+			value = strings.TrimSpace(list[:end])
+			list = list[end:]
+		}
+		attributes[name] = value
+
+		list = strings.TrimLeft(list, " ")
+		if len(list) > 0 {
+			if list[0] != ',' {
+				return attributes, errors.New("Parse Error: expected ',' after attribute " + name)
+			}
+			list = list[1:]
+		}
+	}
+	return attributes, nil
+}
+
+//decodePayload decodes a splice_info_section written in hex(with or without 0x) or base64
+func decodePayload(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		return hex.DecodeString(value[2:])
+	}
+	//a section starts with table_id 0xFC, which is "/" in base64
+	if strings.HasPrefix(strings.ToLower(value), "fc") {
+		if payload, err := hex.DecodeString(value); err == nil {
+			return payload, nil
+		}
+	}
+	if payload, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return payload, nil
+	}
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+//parseSeconds parses a decimal-floating-point number of seconds
+func parseSeconds(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, errors.New("Parse Error: invalid number of seconds: " + value)
+	}
+	return time.Duration(math.Round(seconds * float64(time.Second))), nil
+}
+
+//parseDateTime parses an ISO 8601 date-time, e.g. EXT-X-PROGRAM-DATE-TIME and START-DATE of EXT-X-DATERANGE
+func parseDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02T15:04:05.999999999Z0700", value)
+	if err != nil {
+		return t, errors.New("Parse Error: invalid date-time: " + value)
+	}
+	return t, nil
+}
@@ -0,0 +1,380 @@
+package hls
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+)
+
+//Tags carrying ad markers
+const (
+	TagDateRange    = "EXT-X-DATERANGE"
+	TagCueOut       = "EXT-X-CUE-OUT"
+	TagCueOutCont   = "EXT-X-CUE-OUT-CONT"
+	TagCueIn        = "EXT-X-CUE-IN"
+	TagOATCLSSCTE35 = "EXT-OATCLS-SCTE35"
+	TagAdobeSCTE35  = "EXT-X-SCTE35"
+)
+
+//EventKind tells the role of an ad marker in a break
+type EventKind string
+
+//Kinds of ad markers
+const (
+	KindOut  EventKind = "out"  //start of a break, e.g. SCTE35-OUT, EXT-X-CUE-OUT
+	KindCont EventKind = "cont" //inside a break, e.g. EXT-X-CUE-OUT-CONT
+	KindIn   EventKind = "in"   //end of a break, e.g. SCTE35-IN, EXT-X-CUE-IN
+	KindCmd  EventKind = "cmd"  //the role is given by the splice_info_section only, e.g. SCTE35-CMD, EXT-OATCLS-SCTE35
+)
+
+//Timeline is the segments and the ad markers of a media playlist
+type Timeline struct {
+	MediaSequence uint64     `json:"media_sequence"` //EXT-X-MEDIA-SEQUENCE
+	Segments      []*Segment `json:"segments"`
+	Events        []*Event   `json:"events"` //in the order of the playlist
+}
+
+//Segment is a media segment of the playlist
+type Segment struct {
+	URI             string        `json:"uri"`
+	MediaSequence   uint64        `json:"media_sequence"`
+	Duration        time.Duration `json:"duration"` //EXTINF
+	Offset          time.Duration `json:"offset"`   //sum of the durations of the preceding segments
+	ProgramDateTime *time.Time    `json:"program_date_time,omitempty"`
+	Discontinuity   bool          `json:"discontinuity"`
+}
+
+//Event is an ad marker found in the playlist
+type Event struct {
+	Line int       `json:"line"` //1-based line number of the tag
+	Tag  string    `json:"tag"`  //e.g. EXT-X-DATERANGE
+	Kind EventKind `json:"kind"`
+
+	//SegmentIndex is the index in Timeline.Segments of the segment following the tag, len(Timeline.Segments) if the tag is after the last segment
+	SegmentIndex  int           `json:"segment_index"`
+	MediaSequence uint64        `json:"media_sequence"` //media sequence number of the segment following the tag
+	Offset        time.Duration `json:"offset"`         //playlist time of the segment following the tag
+	//ProgramDateTime is the date-time of the segment following the tag, extrapolated from the preceding segments if the segment has no EXT-X-PROGRAM-DATE-TIME
+	ProgramDateTime *time.Time `json:"program_date_time,omitempty"`
+
+	ID         string            `json:"id,omitempty"`         //ID of EXT-X-DATERANGE or EXT-X-SCTE35
+	StartDate  *time.Time        `json:"start_date,omitempty"` //START-DATE of EXT-X-DATERANGE
+	Duration   *time.Duration    `json:"duration,omitempty"`   //duration of the break if given by the tag
+	Elapsed    *time.Duration    `json:"elapsed,omitempty"`    //elapsed time of the break, EXT-X-CUE-OUT-CONT only
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	Payload []byte        `json:"-"` //splice_info_section embedded in the tag
+	Parser  common.Parser `json:"scte35,omitempty"`
+	Err     error         `json:"-"` //error of parsing the tag or decoding Payload
+}
+
+//ParsePlaylist reads a media playlist and returns its ad markers with the embedded splice_info_sections decoded
+func ParsePlaylist(reader io.Reader) (*Timeline, error) {
+	return ParsePlaylistWithOptions(reader, common.DecodeOptions{})
+}
+
+//ParsePlaylistWithOptions is ParsePlaylist with the splice_info_sections decoded by opts
+//An error of a tag or a payload is reported by Event.Err, the error returned is about reading the playlist
+func ParsePlaylistWithOptions(reader io.Reader, opts common.DecodeOptions) (*Timeline, error) {
+	p := &playlistParser{opts: opts, timeline: &Timeline{}}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+			if line != "#EXTM3U" {
+				return nil, errors.New("Parse Error: playlist must start with #EXTM3U")
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			p.endSegment(line)
+			continue
+		}
+		if strings.HasPrefix(line, "#EXT") {
+			p.handleTag(line, lineNum)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lineNum == 0 {
+		return nil, errors.New("Parse Error: playlist must start with #EXTM3U")
+	}
+
+	p.assignPending()
+	return p.timeline, nil
+}
+
+type playlistParser struct {
+	opts     common.DecodeOptions
+	timeline *Timeline
+
+	//the tags of the coming segment
+	pending         []*Event
+	duration        time.Duration
+	programDateTime *time.Time
+	discontinuity   bool
+
+	offset              time.Duration
+	nextProgramDateTime *time.Time //extrapolated from the last segment
+}
+
+func (p *playlistParser) handleTag(line string, lineNum int) {
+	name, value := line[1:], ""
+	if colon := strings.IndexByte(line, ':'); colon >= 0 {
+		name, value = line[1:colon], line[colon+1:]
+	}
+
+	switch name {
+	case "EXT-X-MEDIA-SEQUENCE":
+		if mediaSequence, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+			p.timeline.MediaSequence = mediaSequence
+		}
+	case "EXTINF":
+		durationStr := value
+		if comma := strings.IndexByte(value, ','); comma >= 0 {
+			durationStr = value[:comma]
+		}
+		if duration, err := parseSeconds(durationStr); err == nil {
+			p.duration = duration
+		}
+	case "EXT-X-PROGRAM-DATE-TIME":
+		if programDateTime, err := parseDateTime(value); err == nil {
+			p.programDateTime = &programDateTime
+		}
+	case "EXT-X-DISCONTINUITY":
+		p.discontinuity = true
+	case TagDateRange:
+		p.handleDateRange(value, lineNum)
+	case TagCueOut, TagCueOutCont, TagCueIn:
+		p.handleCue(name, value, lineNum)
+	case TagOATCLSSCTE35:
+		event := p.newEvent(name, KindCmd, lineNum)
+		p.decode(event, value)
+	case TagAdobeSCTE35:
+		p.handleAdobe(value, lineNum)
+	}
+}
+
+//handleDateRange handles EXT-X-DATERANGE, whose SCTE35-CMD, SCTE35-OUT and SCTE35-IN attributes are hex, see RFC 8216 4.3.2.7.1
+func (p *playlistParser) handleDateRange(value string, lineNum int) {
+	attributes, err := parseAttributes(value)
+
+	var events []*Event
+	for _, attr := range []struct {
+		name string
+		kind EventKind
+	}{{"SCTE35-CMD", KindCmd}, {"SCTE35-OUT", KindOut}, {"SCTE35-IN", KindIn}} {
+		payload, ok := attributes[attr.name]
+		if !ok {
+			continue
+		}
+		event := p.newEvent(TagDateRange, attr.kind, lineNum)
+		event.Attributes = attributes
+		event.ID = attributes["ID"]
+		event.Err = err
+		p.decode(event, payload)
+		events = append(events, event)
+	}
+
+	for _, event := range events {
+		if startDate, ok := attributes["START-DATE"]; ok {
+			if t, err := parseDateTime(startDate); err == nil {
+				event.StartDate = &t
+			} else if event.Err == nil {
+				event.Err = err
+			}
+		}
+		for _, name := range []string{"DURATION", "PLANNED-DURATION"} {
+			if s, ok := attributes[name]; ok && event.Duration == nil {
+				if duration, err := parseSeconds(s); err == nil {
+					event.Duration = &duration
+				}
+			}
+		}
+	}
+}
+
+//handleCue handles EXT-X-CUE-OUT, EXT-X-CUE-OUT-CONT and EXT-X-CUE-IN
+//The value is either a bare number of seconds(e.g. EXT-X-CUE-OUT:30, EXT-X-CUE-OUT-CONT:8.308/30) or an attribute-list
+//(e.g. EXT-X-CUE-OUT:DURATION=30, EXT-X-CUE-OUT-CONT:ElapsedTime=8.308,Duration=30,SCTE35=/DAl...)
+func (p *playlistParser) handleCue(name string, value string, lineNum int) {
+	kind := KindOut
+	switch name {
+	case TagCueOutCont:
+		kind = KindCont
+	case TagCueIn:
+		kind = KindIn
+	}
+	event := p.newEvent(name, kind, lineNum)
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if !strings.Contains(value, "=") {
+		elapsedStr, durationStr := "", value
+		if slash := strings.IndexByte(value, '/'); slash >= 0 {
+			elapsedStr, durationStr = value[:slash], value[slash+1:]
+		}
+		duration, err := parseSeconds(durationStr)
+		if err != nil {
+			event.Err = err
+			return
+		}
+		event.Duration = &duration
+		if elapsedStr != "" {
+			elapsed, err := parseSeconds(elapsedStr)
+			if err != nil {
+				event.Err = err
+				return
+			}
+			event.Elapsed = &elapsed
+		}
+		return
+	}
+
+	attributes, err := parseAttributes(value)
+	event.Attributes = attributes
+	event.Err = err
+	if s, ok := lookup(attributes, "DURATION"); ok {
+		if duration, err := parseSeconds(s); err == nil {
+			event.Duration = &duration
+		}
+	}
+	if s, ok := lookup(attributes, "ELAPSEDTIME"); ok {
+		if elapsed, err := parseSeconds(s); err == nil {
+			event.Elapsed = &elapsed
+		}
+	}
+	if payload, ok := lookup(attributes, "SCTE35"); ok {
+		p.decode(event, payload)
+	}
+}
+
+//handleAdobe handles EXT-X-SCTE35 of Adobe Primetime, e.g. EXT-X-SCTE35:CUE="/DAl...",ID="123",DURATION=30,CUE-OUT=YES
+func (p *playlistParser) handleAdobe(value string, lineNum int) {
+	attributes, err := parseAttributes(value)
+
+	kind := KindCmd
+	if s, ok := lookup(attributes, "CUE-OUT"); ok {
+		kind = KindOut
+		if strings.EqualFold(s, "CONT") {
+			kind = KindCont
+		}
+	} else if _, ok := lookup(attributes, "CUE-IN"); ok {
+		kind = KindIn
+	}
+
+	event := p.newEvent(TagAdobeSCTE35, kind, lineNum)
+	event.Attributes = attributes
+	event.ID = attributes["ID"]
+	event.Err = err
+	if s, ok := lookup(attributes, "DURATION"); ok {
+		if duration, err := parseSeconds(s); err == nil {
+			event.Duration = &duration
+		}
+	}
+	if payload, ok := lookup(attributes, "CUE"); ok {
+		p.decode(event, payload)
+	}
+}
+
+func (p *playlistParser) newEvent(tag string, kind EventKind, lineNum int) *Event {
+	event := &Event{Line: lineNum, Tag: tag, Kind: kind}
+	p.timeline.Events = append(p.timeline.Events, event)
+	p.pending = append(p.pending, event)
+	return event
+}
+
+//decode decodes the payload of event, the first error of the tag is kept
+func (p *playlistParser) decode(event *Event, value string) {
+	payload, err := decodePayload(value)
+	if err != nil {
+		if event.Err == nil {
+			event.Err = errors.New("Parse Error: line " + strconv.Itoa(event.Line) + ": payload is neither hex nor base64")
+		}
+		return
+	}
+	event.Payload = payload
+
+	parser, err := decoder.DecodeWithOptions(payload, p.opts)
+	event.Parser = parser
+	if event.Err == nil {
+		event.Err = err
+	}
+}
+
+//endSegment is called with the URI line, which completes a media segment
+func (p *playlistParser) endSegment(uri string) {
+	segment := &Segment{
+		URI:           uri,
+		MediaSequence: p.timeline.MediaSequence + uint64(len(p.timeline.Segments)),
+		Duration:      p.duration,
+		Offset:        p.offset,
+		Discontinuity: p.discontinuity,
+	}
+	segment.ProgramDateTime = p.segmentProgramDateTime()
+	p.assignPending()
+	p.timeline.Segments = append(p.timeline.Segments, segment)
+
+	p.offset += segment.Duration
+	p.nextProgramDateTime = nil
+	if segment.ProgramDateTime != nil {
+		next := segment.ProgramDateTime.Add(segment.Duration)
+		p.nextProgramDateTime = &next
+	}
+	p.duration = 0
+	p.programDateTime = nil
+	p.discontinuity = false
+}
+
+//segmentProgramDateTime returns the date-time of the coming segment, it is not extrapolated across a discontinuity
+func (p *playlistParser) segmentProgramDateTime() *time.Time {
+	if p.programDateTime != nil {
+		return p.programDateTime
+	}
+	if p.discontinuity {
+		return nil
+	}
+	return p.nextProgramDateTime
+}
+
+//assignPending sets the position of the tags of the coming segment
+func (p *playlistParser) assignPending() {
+	programDateTime := p.segmentProgramDateTime()
+	for _, event := range p.pending {
+		event.SegmentIndex = len(p.timeline.Segments)
+		event.MediaSequence = p.timeline.MediaSequence + uint64(len(p.timeline.Segments))
+		event.Offset = p.offset
+		event.ProgramDateTime = programDateTime
+	}
+	p.pending = nil
+}
+
+//lookup returns the value of the attribute whose name matches name case-insensitively
+func lookup(attributes map[string]string, name string) (value string, ok bool) {
+	if value, ok = attributes[name]; ok {
+		return value, true
+	}
+	for key, value := range attributes {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
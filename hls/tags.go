@@ -0,0 +1,229 @@
+package hls
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	schema_2017 "github.com/chanyk-joseph/scte35_decoder/2017"
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//dateTimeLayout is the layout of START-DATE written by DateRange
+const dateTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+//breakStartTypes are the segmentation_type_id which start a break, the segmentation_type_id + 1 of each ends it
+var breakStartTypes = map[common.SegmentationTypeID]bool{
+	0x22: true, //Break Start
+	0x30: true, //Provider Advertisement Start
+	0x32: true, //Distributor Advertisement Start
+	0x34: true, //Provider Placement Opportunity Start
+	0x36: true, //Distributor Placement Opportunity Start
+	0x38: true, //Provider Overlay Placement Opportunity Start
+	0x3A: true, //Distributor Overlay Placement Opportunity Start
+	0x44: true, //Provider Ad Block Start
+	0x46: true, //Distributor Ad Block Start
+}
+
+//DateRange is an EXT-X-DATERANGE tag carrying a splice_info_section, see RFC 8216 4.3.2.7
+type DateRange struct {
+	ID              string
+	Class           string //omitted if empty
+	StartDate       time.Time
+	Duration        *time.Duration
+	PlannedDuration *time.Duration
+
+	SCTE35Cmd []byte
+	SCTE35Out []byte
+	SCTE35In  []byte
+}
+
+//String renders the tag, e.g. #EXT-X-DATERANGE:ID="splice-6FFFFFF0",START-DATE="2014-03-05T11:15:00.000Z",PLANNED-DURATION=59.993,SCTE35-OUT=0xFC...
+func (dateRange *DateRange) String() string {
+	attributes := []string{"ID=" + strconv.Quote(dateRange.ID)}
+	if dateRange.Class != "" {
+		attributes = append(attributes, "CLASS="+strconv.Quote(dateRange.Class))
+	}
+	attributes = append(attributes, "START-DATE="+strconv.Quote(dateRange.StartDate.Format(dateTimeLayout)))
+	if dateRange.Duration != nil {
+		attributes = append(attributes, "DURATION="+formatSeconds(*dateRange.Duration))
+	}
+	if dateRange.PlannedDuration != nil {
+		attributes = append(attributes, "PLANNED-DURATION="+formatSeconds(*dateRange.PlannedDuration))
+	}
+	for _, attr := range []struct {
+		name    string
+		payload []byte
+	}{{"SCTE35-CMD", dateRange.SCTE35Cmd}, {"SCTE35-OUT", dateRange.SCTE35Out}, {"SCTE35-IN", dateRange.SCTE35In}} {
+		if attr.payload != nil {
+			attributes = append(attributes, attr.name+"=0x"+strings.ToUpper(hex.EncodeToString(attr.payload)))
+		}
+	}
+	return "#" + TagDateRange + ":" + strings.Join(attributes, ",")
+}
+
+//Cue is a splice_info_section to be rendered, together with the wall-clock time of its splice point
+type Cue struct {
+	SCTE35 *schema_2017.SCTE35
+	Time   time.Time
+}
+
+//TagOptions controls the tags rendered by TagRenderer and RenderTags
+type TagOptions struct {
+	//Class is the CLASS attribute of EXT-X-DATERANGE, omitted if empty
+	Class string
+	//Legacy adds EXT-X-CUE-OUT and EXT-X-CUE-IN after EXT-X-DATERANGE for the players which do not support it
+	Legacy bool
+}
+
+//CueKind tells whether cue starts a break(splice_insert with out_of_network_indicator, or a segmentation descriptor of a break start type),
+//ends a break, or neither, together with the ID of the event and the planned duration of the break
+func CueKind(cue *schema_2017.SCTE35) (kind EventKind, id string, duration *time.Duration) {
+	if spliceInsert := cue.SpliceInsert; spliceInsert != nil {
+		id = "splice-" + strings.ToUpper(strconv.FormatUint(uint64(spliceInsert.SpliceEventID), 16))
+		if spliceInsert.SpliceEventCancelIndicator || spliceInsert.OutOfNetworkIndicator == nil {
+			return KindCmd, id, nil
+		}
+		if spliceInsert.BreakDuration != nil {
+			d := spliceInsert.BreakDuration.TimeDuration()
+			duration = &d
+		}
+		if *spliceInsert.OutOfNetworkIndicator {
+			return KindOut, id, duration
+		}
+		return KindIn, id, nil
+	}
+
+	kind = KindCmd
+	for _, spliceDesc := range cue.SpliceDescriptors {
+		segDesc := spliceDesc.SegmentationDescriptor
+		if segDesc == nil {
+			continue
+		}
+		segID := "segmentation-" + strings.ToUpper(strconv.FormatUint(uint64(segDesc.SegmentationEventID), 16))
+		if id == "" {
+			id = segID
+		}
+		if segDesc.SegmentationEventCancelIndicator || segDesc.SegmentationTypeID == nil {
+			continue
+		}
+		typeID := *segDesc.SegmentationTypeID
+		if breakStartTypes[typeID] {
+			if d, ok := segDesc.SegmentationTimeDuration(); ok {
+				duration = &d
+			}
+			return KindOut, segID, duration
+		}
+		if typeID > 0 && breakStartTypes[typeID-1] {
+			return KindIn, segID, nil
+		}
+	}
+	return kind, id, nil
+}
+
+//NewDateRange returns the EXT-X-DATERANGE of cue, the splice_info_section is re-encoded
+func NewDateRange(cue Cue, class string) (dateRange *DateRange, kind EventKind, err error) {
+	if cue.SCTE35 == nil {
+		return nil, "", errors.New("Encode Error: SCTE35 of the cue is nil")
+	}
+	payload, err := cue.SCTE35.EncodeToRawBytes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	kind, id, duration := CueKind(cue.SCTE35)
+	if id == "" {
+		id = "scte35-" + strconv.FormatInt(cue.Time.UnixNano()/int64(time.Millisecond), 10)
+	}
+	dateRange = &DateRange{ID: id, Class: class, StartDate: cue.Time}
+	switch kind {
+	case KindOut:
+		dateRange.SCTE35Out = payload
+		dateRange.PlannedDuration = duration
+	case KindIn:
+		dateRange.SCTE35In = payload
+	default:
+		dateRange.SCTE35Cmd = payload
+	}
+	return dateRange, kind, nil
+}
+
+//TagRenderer renders the ad markers of cues one at a time, e.g. while a live playlist is decorated segment by segment
+//The EXT-X-DATERANGE starting a break is kept until the cue ending it, so that the tag ending the break repeats its START-DATE and tells the actual DURATION, as required by RFC 8216 4.3.2.7
+type TagRenderer struct {
+	opts TagOptions
+	outs map[string]*DateRange //ID -> the tag starting a break which is not ended yet
+}
+
+//NewTagRenderer returns a TagRenderer without open breaks
+func NewTagRenderer(opts TagOptions) *TagRenderer {
+	return &TagRenderer{opts: opts, outs: map[string]*DateRange{}}
+}
+
+//Open returns the tags starting the breaks which are not ended yet
+func (r *TagRenderer) Open() (outs []*DateRange) {
+	for _, out := range r.outs {
+		outs = append(outs, out)
+	}
+	return outs
+}
+
+//Resume adds the tags starting breaks rendered elsewhere(e.g. before a restart), so that the cues ending them are paired
+func (r *TagRenderer) Resume(outs ...*DateRange) {
+	for _, out := range outs {
+		r.outs[out.ID] = out
+	}
+}
+
+//Render returns the lines to be written before the segment containing the splice point of cue
+//With TagOptions.Legacy, EXT-X-CUE-OUT is only written if the planned duration of the break is known, and EXT-X-CUE-IN only ends such a break
+func (r *TagRenderer) Render(cue Cue) (lines []string, err error) {
+	dateRange, kind, err := NewDateRange(cue, r.opts.Class)
+	if err != nil {
+		return nil, err
+	}
+
+	var out *DateRange
+	switch kind {
+	case KindOut:
+		r.outs[dateRange.ID] = dateRange
+	case KindIn:
+		if out = r.outs[dateRange.ID]; out != nil {
+			duration := cue.Time.Sub(out.StartDate)
+			dateRange.StartDate = out.StartDate
+			dateRange.Duration = &duration
+			dateRange.PlannedDuration = out.PlannedDuration
+			delete(r.outs, dateRange.ID)
+		}
+	}
+
+	lines = []string{dateRange.String()}
+	if r.opts.Legacy && dateRange.PlannedDuration != nil {
+		switch {
+		case kind == KindOut:
+			lines = append(lines, "#"+TagCueOut+":DURATION="+formatSeconds(*dateRange.PlannedDuration))
+		case kind == KindIn && out != nil:
+			lines = append(lines, "#"+TagCueIn)
+		}
+	}
+	return lines, nil
+}
+
+//RenderTags renders the ad markers of cues by a new TagRenderer, tags[i] are the lines to be written before the segment containing the splice point of cues[i]
+func RenderTags(cues []Cue, opts TagOptions) (tags [][]string, err error) {
+	renderer := NewTagRenderer(opts)
+	for i, cue := range cues {
+		lines, err := renderer.Render(cue)
+		if err != nil {
+			return tags, errors.New("Encode Error: cues[" + strconv.Itoa(i) + "]: " + err.Error())
+		}
+		tags = append(tags, lines)
+	}
+	return tags, nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,242 @@
+package mpegts
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+	"github.com/chanyk-joseph/scte35_decoder/decoder"
+)
+
+//Cue is a splice_info_section found in a transport stream
+type Cue struct {
+	PID           uint16  `json:"pid"`
+	ProgramNumber uint16  `json:"program_number"`
+	PacketIndex   int     `json:"packet_index"`  //index of the packet carrying the last byte of the section
+	PCR           *uint64 `json:"pcr,omitempty"` //the most recent PCR of the program before the section is completed, 27MHz
+
+	Section []byte        `json:"-"`
+	Parser  common.Parser `json:"scte35,omitempty"`
+	Err     error         `json:"-"` //error of decoding Section, Parser may hold a partial result in lenient mode
+}
+
+//Demuxer reads transport stream packets and yields the splice_info_sections carried by the elementary streams with stream_type 0x86
+type Demuxer struct {
+	//DecodeOptions is used to decode the splice_info_sections
+	DecodeOptions common.DecodeOptions
+	//NewParser creates the parser of a fixed schema, the schema is detected by decoder.DecodeWithOptions if it is nil
+	NewParser func() decoder.SchemaParser
+	//PacketHandler is called with every packet before it is demuxed, e.g. to track the PTS of other elementary streams
+	//program is nil if the PID is not announced by any PMT yet. pkt.Payload is only valid during the call
+	PacketHandler func(pkt *Packet, program *Program)
+
+	reader      io.Reader
+	buf         [PacketSize]byte
+	packetIndex int
+
+	assemblers  map[uint16]*sectionAssembler
+	patVersion  uint8
+	patSections map[uint8]*patSection //section_number -> section of patVersion
+	pmtPIDs     map[uint16]uint16     //PMT PID -> program_number
+	programs    map[uint16]*Program   //program_number -> program
+	programOf   map[uint16]*Program   //elementary stream or PCR PID -> program
+	scte35PIDs  map[uint16]bool
+	pcrs        map[uint16]uint64 //PCR PID -> the most recent PCR
+
+	pending []*Cue
+}
+
+//NewDemuxer returns a Demuxer reading 188 bytes packets from reader
+func NewDemuxer(reader io.Reader) *Demuxer {
+	return &Demuxer{
+		reader:     reader,
+		assemblers: map[uint16]*sectionAssembler{},
+		pmtPIDs:    map[uint16]uint16{},
+		programs:   map[uint16]*Program{},
+		programOf:  map[uint16]*Program{},
+		scte35PIDs: map[uint16]bool{},
+		pcrs:       map[uint16]uint64{},
+	}
+}
+
+//Programs returns the programs found so far, keyed by program_number
+func (d *Demuxer) Programs() map[uint16]*Program {
+	return d.programs
+}
+
+//PacketIndex returns the number of packets read so far
+func (d *Demuxer) PacketIndex() int {
+	return d.packetIndex
+}
+
+//Next returns the next cue, io.EOF is returned at the end of the stream
+//An error decoding a section is reported by Cue.Err, the error returned by Next is about reading the stream
+func (d *Demuxer) Next() (*Cue, error) {
+	for len(d.pending) == 0 {
+		if err := d.step(); err != nil {
+			return nil, err
+		}
+	}
+
+	cue := d.pending[0]
+	d.pending = d.pending[1:]
+	return cue, nil
+}
+
+//step reads and demuxes one packet, the cues completed by it are appended to pending
+func (d *Demuxer) step() error {
+	if err := d.readPacket(); err != nil {
+		return err
+	}
+
+	pkt := &Packet{Index: d.packetIndex}
+	d.packetIndex++
+	if _, err := pkt.DecodeFromRawBytes(d.buf[:]); err != nil {
+		return err
+	}
+	d.handlePacket(pkt)
+	return nil
+}
+
+//ReadAll returns all cues until the end of the stream
+func (d *Demuxer) ReadAll() (cues []*Cue, err error) {
+	for {
+		cue, err := d.Next()
+		if err == io.EOF {
+			return cues, nil
+		}
+		if err != nil {
+			return cues, err
+		}
+		cues = append(cues, cue)
+	}
+}
+
+//readPacket reads the next packet into buf, the stream is resynchronized at the next sync byte if sync is lost
+func (d *Demuxer) readPacket() error {
+	if _, err := io.ReadFull(d.reader, d.buf[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return errors.New("Parse Error: the stream ends in the middle of packet " + strconv.Itoa(d.packetIndex))
+		}
+		return err
+	}
+
+	for d.buf[0] != SyncByte {
+		offset := bytes.IndexByte(d.buf[1:], SyncByte) + 1
+		if offset == 0 {
+			offset = PacketSize
+		}
+		copy(d.buf[:], d.buf[offset:])
+		if _, err := io.ReadFull(d.reader, d.buf[PacketSize-offset:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return io.EOF //no more packet can be synchronized
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Demuxer) handlePacket(pkt *Packet) {
+	program := d.programOf[pkt.PID]
+	if d.PacketHandler != nil {
+		d.PacketHandler(pkt, program)
+	}
+	if pkt.TransportErrorIndicator {
+		return
+	}
+
+	if pkt.PCR != nil {
+		d.pcrs[pkt.PID] = *pkt.PCR
+	}
+
+	_, isPMT := d.pmtPIDs[pkt.PID]
+	if pkt.PID != 0x0000 && !isPMT && !d.scte35PIDs[pkt.PID] {
+		return
+	}
+
+	assembler, ok := d.assemblers[pkt.PID]
+	if !ok {
+		assembler = &sectionAssembler{}
+		d.assemblers[pkt.PID] = assembler
+	}
+	for _, section := range assembler.push(pkt) {
+		switch {
+		case pkt.PID == 0x0000:
+			d.handlePAT(section)
+		case isPMT:
+			d.handlePMT(section, pkt.PID)
+		default:
+			d.handleSCTE35(section, pkt, program)
+		}
+	}
+}
+
+//handlePAT merges the programs of every section of the current PAT version, the sections of the previous version are dropped once the version changes
+func (d *Demuxer) handlePAT(section []byte) {
+	pat, err := decodePAT(section)
+	if err != nil || pat == nil {
+		return
+	}
+	if d.patSections == nil || pat.versionNumber != d.patVersion {
+		d.patSections = map[uint8]*patSection{}
+		d.patVersion = pat.versionNumber
+	}
+	d.patSections[pat.sectionNumber] = pat
+
+	d.pmtPIDs = map[uint16]uint16{}
+	for _, pat := range d.patSections {
+		for programNumber, pid := range pat.pmtPIDs {
+			d.pmtPIDs[pid] = programNumber
+		}
+	}
+}
+
+func (d *Demuxer) handlePMT(section []byte, pmtPID uint16) {
+	program, err := decodePMT(section, pmtPID)
+	if err != nil || program == nil {
+		return
+	}
+
+	if previous, ok := d.programs[program.ProgramNumber]; ok {
+		delete(d.programOf, previous.PCRPID)
+		for _, stream := range previous.Streams {
+			delete(d.programOf, stream.PID)
+			delete(d.scte35PIDs, stream.PID)
+		}
+	}
+	d.programs[program.ProgramNumber] = program
+
+	d.programOf[program.PCRPID] = program
+	for _, stream := range program.Streams {
+		d.programOf[stream.PID] = program
+		if stream.StreamType == StreamTypeSCTE35 {
+			d.scte35PIDs[stream.PID] = true
+		}
+	}
+}
+
+func (d *Demuxer) handleSCTE35(section []byte, pkt *Packet, program *Program) {
+	if section[0] != 0xFC {
+		return //not a splice_info_section
+	}
+
+	cue := &Cue{PID: pkt.PID, PacketIndex: pkt.Index, Section: section}
+	if program != nil {
+		cue.ProgramNumber = program.ProgramNumber
+		if pcr, ok := d.pcrs[program.PCRPID]; ok {
+			cue.PCR = &pcr
+		}
+	}
+
+	if d.NewParser != nil {
+		parser := d.NewParser()
+		_, cue.Err = parser.DecodeFromRawBytesWithOptions(section, d.DecodeOptions)
+		cue.Parser = parser
+	} else {
+		cue.Parser, cue.Err = decoder.DecodeWithOptions(section, d.DecodeOptions)
+	}
+	d.pending = append(d.pending, cue)
+}
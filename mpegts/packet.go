@@ -0,0 +1,89 @@
+package mpegts
+
+import (
+	"errors"
+	"strconv"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//PacketSize is the size of a transport stream packet
+const PacketSize = 188
+
+//SyncByte is the first byte of every transport stream packet
+const SyncByte = 0x47
+
+//PCRClockRate is the frequency of program_clock_reference, PCR = PCR_base * 300 + PCR_extension
+const PCRClockRate = 27000000
+
+//Packet is a transport stream packet defined in ISO/IEC 13818-1 2.4.3.2
+type Packet struct {
+	Index int //index of the packet in the stream, starting from 0
+
+	TransportErrorIndicator   bool
+	PayloadUnitStartIndicator bool
+	PID                       uint16 //13 bits
+	AdaptationFieldControl    uint8  //2 bits
+	ContinuityCounter         uint8  //4 bits
+
+	DiscontinuityIndicator bool
+	PCR                    *uint64 //27MHz, present if PCR_flag of adaptation field is set
+
+	Payload []byte
+}
+
+//HasPayload reports whether adaptation_field_control indicates a payload
+func (pkt *Packet) HasPayload() bool {
+	return pkt.AdaptationFieldControl&0x01 != 0
+}
+
+//DecodeFromRawBytes parses a 188 bytes transport stream packet
+func (pkt *Packet) DecodeFromRawBytes(input []byte) (numOfParsedBits int, err error) {
+	if len(input) != PacketSize {
+		return 0, errors.New("Parse Error: transport stream packet must be " + strconv.Itoa(PacketSize) + " bytes, got " + strconv.Itoa(len(input)))
+	}
+
+	r := common.NewBitReader(input)
+	if syncByte := r.Uint8("sync_byte"); syncByte != SyncByte {
+		return 0, errors.New("Parse Error: sync_byte of packet " + strconv.Itoa(pkt.Index) + " is 0x" + strconv.FormatUint(uint64(syncByte), 16))
+	}
+	pkt.TransportErrorIndicator = r.Bool("transport_error_indicator")
+	pkt.PayloadUnitStartIndicator = r.Bool("payload_unit_start_indicator")
+	r.Bool("transport_priority")
+	pkt.PID = uint16(r.Bits("pid", 13))
+	r.Bits("transport_scrambling_control", 2)
+	pkt.AdaptationFieldControl = uint8(r.Bits("adaptation_field_control", 2))
+	pkt.ContinuityCounter = uint8(r.Bits("continuity_counter", 4))
+
+	if pkt.AdaptationFieldControl&0x02 != 0 {
+		adaptationFieldLength := int(r.Uint8("adaptation_field_length"))
+		sub := r.Sub("adaptation_field", adaptationFieldLength*8)
+		if adaptationFieldLength > 0 {
+			pkt.DiscontinuityIndicator = sub.Bool("discontinuity_indicator")
+			sub.Bool("random_access_indicator")
+			sub.Bool("elementary_stream_priority_indicator")
+			pcrFlag := sub.Bool("PCR_flag")
+			sub.Bits("flags", 4)
+
+			if pcrFlag {
+				pcrBase := sub.Bits("program_clock_reference_base", 33)
+				sub.Reserved(6)
+				pcrExtension := sub.Bits("program_clock_reference_extension", 9)
+				pcr := pcrBase*300 + pcrExtension
+				pkt.PCR = &pcr
+			}
+		}
+		r.SetErr(sub.Err())
+		r.Skip("adaptation_field", adaptationFieldLength*8)
+	}
+
+	if r.Err() != nil {
+		return 0, r.Err()
+	}
+
+	pkt.Payload = nil
+	if pkt.HasPayload() {
+		pkt.Payload = input[r.Pos()/8:]
+	}
+	return PacketSize * 8, nil
+}
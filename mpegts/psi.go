@@ -0,0 +1,220 @@
+package mpegts
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//StreamTypeSCTE35 is the stream_type of SCTE 35 elementary streams in PMT
+const StreamTypeSCTE35 = 0x86
+
+//Program is a program announced by PAT, whose streams are listed by its PMT
+type Program struct {
+	ProgramNumber uint16   `json:"program_number"`
+	PMTPID        uint16   `json:"pmt_pid"`
+	PCRPID        uint16   `json:"pcr_pid"`
+	Streams       []Stream `json:"streams"`
+}
+
+//Stream is an elementary stream of a program
+type Stream struct {
+	StreamType uint8  `json:"stream_type"`
+	PID        uint16 `json:"pid"`
+}
+
+//sectionAssembler reassembles the PSI sections carried by the packets of a PID, see ISO/IEC 13818-1 2.4.4
+type sectionAssembler struct {
+	buf       []byte
+	started   bool
+	lastCC    uint8
+	hasLastCC bool
+}
+
+func (a *sectionAssembler) reset() {
+	a.buf = nil
+	a.started = false
+}
+
+//push feeds the payload of pkt and returns the sections completed by it
+func (a *sectionAssembler) push(pkt *Packet) (sections [][]byte) {
+	if !pkt.HasPayload() {
+		return nil
+	}
+	if a.hasLastCC && !pkt.DiscontinuityIndicator {
+		if pkt.ContinuityCounter == a.lastCC {
+			return nil //duplicate packet
+		}
+		if pkt.ContinuityCounter != (a.lastCC+1)&0x0f {
+			a.reset() //packet loss, the partial section is dropped
+		}
+	}
+	a.lastCC, a.hasLastCC = pkt.ContinuityCounter, true
+
+	payload := pkt.Payload
+	if pkt.PayloadUnitStartIndicator {
+		if len(payload) == 0 {
+			a.reset()
+			return nil
+		}
+		pointerField := int(payload[0])
+		payload = payload[1:]
+		if pointerField > len(payload) {
+			a.reset()
+			return nil
+		}
+
+		//the bytes before pointer_field complete the previous section
+		if a.started {
+			a.buf = append(a.buf, payload[:pointerField]...)
+			sections = a.extract()
+		}
+		a.buf = append([]byte{}, payload[pointerField:]...)
+		a.started = true
+	} else if a.started {
+		a.buf = append(a.buf, payload...)
+	}
+
+	return append(sections, a.extract()...)
+}
+
+//extract removes the complete sections from the head of buf, a section may be followed by another one or by 0xFF stuffing bytes
+func (a *sectionAssembler) extract() (sections [][]byte) {
+	for a.started && len(a.buf) > 0 {
+		if a.buf[0] == 0xff {
+			a.reset()
+			break
+		}
+		if len(a.buf) < 3 {
+			break
+		}
+		sectionLength := 3 + int(binary.BigEndian.Uint16(a.buf[1:3])&0x0fff)
+		if len(a.buf) < sectionLength {
+			break
+		}
+		sections = append(sections, a.buf[:sectionLength:sectionLength])
+		a.buf = a.buf[sectionLength:]
+	}
+	if len(a.buf) == 0 {
+		a.reset()
+	}
+	return sections
+}
+
+//verifySectionCRC32 checks CRC_32 of a long-form PSI section
+func verifySectionCRC32(section []byte) error {
+	if len(section) < 4 {
+		return errors.New("Parse Error: section is shorter than CRC_32")
+	}
+	if common.CRC32MPEG2(section[:len(section)-4]) != binary.BigEndian.Uint32(section[len(section)-4:]) {
+		return errors.New("CRC32 Mismatch: table_id 0x" + strconv.FormatUint(uint64(section[0]), 16))
+	}
+	return nil
+}
+
+//patSection is a program_association_section, a PAT may be split into several sections of the same version_number
+type patSection struct {
+	versionNumber uint8
+	sectionNumber uint8
+	pmtPIDs       map[uint16]uint16 //program_number -> PMT PID
+}
+
+//decodePAT parses program_association_section and returns the PMT PID of every program in it, nil if current_next_indicator is not set
+func decodePAT(section []byte) (pat *patSection, err error) {
+	if err = verifySectionCRC32(section); err != nil {
+		return nil, err
+	}
+
+	r := common.NewBitReader(section)
+	if tableID := r.Uint8("table_id"); tableID != 0x00 {
+		return nil, errors.New("Parse Error: table_id of PAT must be 0x00, got 0x" + strconv.FormatUint(uint64(tableID), 16))
+	}
+	r.Bits("section_syntax_indicator", 2)
+	r.Reserved(2)
+	sectionLength := int(r.Bits("section_length", 12))
+	r.Uint16("transport_stream_id")
+	r.Reserved(2)
+	versionNumber := uint8(r.Bits("version_number", 5))
+	currentNextIndicator := r.Bool("current_next_indicator")
+	sectionNumber := r.Uint8("section_number")
+	r.Uint8("last_section_number")
+	if !currentNextIndicator {
+		return nil, r.Err()
+	}
+
+	pat = &patSection{versionNumber: versionNumber, sectionNumber: sectionNumber, pmtPIDs: map[uint16]uint16{}}
+	loop := r.Sub("programs", nonNegative(sectionLength-5-4)*8)
+	for loop.Err() == nil && loop.Left() >= 32 {
+		programNumber := loop.Uint16("program_number")
+		loop.Reserved(3)
+		pid := uint16(loop.Bits("pid", 13))
+		if programNumber != 0 { //program_number 0 is network_PID
+			pat.pmtPIDs[programNumber] = pid
+		}
+	}
+	r.Merge(loop)
+
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	return pat, nil
+}
+
+//decodePMT parses TS_program_map_section
+func decodePMT(section []byte, pmtPID uint16) (program *Program, err error) {
+	if err = verifySectionCRC32(section); err != nil {
+		return nil, err
+	}
+
+	r := common.NewBitReader(section)
+	if tableID := r.Uint8("table_id"); tableID != 0x02 {
+		return nil, errors.New("Parse Error: table_id of PMT must be 0x02, got 0x" + strconv.FormatUint(uint64(tableID), 16))
+	}
+	r.Bits("section_syntax_indicator", 2)
+	r.Reserved(2)
+	sectionLength := int(r.Bits("section_length", 12))
+
+	program = &Program{PMTPID: pmtPID}
+	program.ProgramNumber = r.Uint16("program_number")
+	r.Reserved(2)
+	r.Bits("version_number", 5)
+	currentNextIndicator := r.Bool("current_next_indicator")
+	r.Uint8("section_number")
+	r.Uint8("last_section_number")
+	r.Reserved(3)
+	program.PCRPID = uint16(r.Bits("PCR_PID", 13))
+	r.Reserved(4)
+	programInfoLength := int(r.Bits("program_info_length", 12))
+	r.Skip("program_info", programInfoLength*8)
+	if !currentNextIndicator {
+		return nil, r.Err()
+	}
+
+	loop := r.Sub("streams", nonNegative(sectionLength-9-programInfoLength-4)*8)
+	for loop.Err() == nil && loop.Left() >= 40 {
+		stream := Stream{}
+		stream.StreamType = loop.Uint8("stream_type")
+		loop.Reserved(3)
+		stream.PID = uint16(loop.Bits("elementary_PID", 13))
+		loop.Reserved(4)
+		esInfoLength := int(loop.Bits("ES_info_length", 12))
+		loop.Skip("ES_info", esInfoLength*8)
+
+		program.Streams = append(program.Streams, stream)
+	}
+	r.Merge(loop)
+
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	return program, nil
+}
+
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
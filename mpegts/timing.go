@@ -0,0 +1,225 @@
+package mpegts
+
+import (
+	"io"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//RecommendedPreroll is the minimum time between the arrival of a cue and its splice point recommended by SCTE 67
+const RecommendedPreroll = 4 * time.Second
+
+//nearestVideoPTSWindow is how far the video must go beyond the splice point before the nearest frame is settled,
+//frames are not in presentation order when B-frames are used
+const nearestVideoPTSWindow = common.PTSClockRate
+
+//videoStreamTypes are the stream_type of video elementary streams
+var videoStreamTypes = map[uint8]bool{
+	0x01: true, //MPEG-1 video
+	0x02: true, //MPEG-2 video
+	0x10: true, //MPEG-4 visual
+	0x1B: true, //H.264
+	0x24: true, //H.265
+	0x33: true, //H.266
+	0x42: true, //AVS
+	0xEA: true, //VC-1
+}
+
+//CueTiming tells when a cue arrives and when its splice point lands relative to the video
+//The arrival time of the cue is Cue.PCR, the most recent PCR of the program when the cue is completed
+type CueTiming struct {
+	*Cue
+
+	ArrivalPTS *uint64 `json:"arrival_pts,omitempty"` //Cue.PCR in 90kHz
+	//SplicePTS is the splice time of time_signal or splice_insert with pts_adjustment applied, nil for immediate splices and other commands
+	SplicePTS *uint64 `json:"splice_pts,omitempty"`
+	//Preroll is SplicePTS minus ArrivalPTS
+	Preroll             *time.Duration `json:"preroll,omitempty"`
+	InsufficientPreroll bool           `json:"insufficient_preroll"` //Preroll is less than MinPreroll of the Correlator
+
+	VideoPID        *uint16 `json:"video_pid,omitempty"`
+	NearestVideoPTS *uint64 `json:"nearest_video_pts,omitempty"` //PTS of the video frame closest to SplicePTS
+}
+
+//Correlator tracks the PCR and the video PTS of every program while demuxing, and reports the timing of every cue
+type Correlator struct {
+	*Demuxer
+
+	//MinPreroll is the preroll below which a cue is flagged by InsufficientPreroll, RecommendedPreroll by default
+	MinPreroll time.Duration
+
+	pending []*CueTiming
+	eof     bool
+}
+
+//NewCorrelator returns a Correlator reading 188 bytes packets from reader
+func NewCorrelator(reader io.Reader) *Correlator {
+	c := &Correlator{Demuxer: NewDemuxer(reader), MinPreroll: RecommendedPreroll}
+	c.PacketHandler = c.handlePacket
+	return c
+}
+
+//Next returns the timing of the next cue, io.EOF is returned at the end of the stream
+//As the nearest video frame is only known after the splice point is reached, the timing of a cue may be returned some packets after the cue.
+//Each cue is returned as soon as it is settled, so a cue with a long preroll does not hold back the later ones; the settled cues are returned in arrival order
+func (c *Correlator) Next() (*CueTiming, error) {
+	for {
+		for i, timing := range c.pending {
+			if c.eof || c.settled(timing) {
+				c.pending = append(c.pending[:i], c.pending[i+1:]...)
+				return timing, nil
+			}
+		}
+		if c.eof {
+			return nil, io.EOF
+		}
+
+		err := c.Demuxer.step()
+		if err == io.EOF {
+			c.eof = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, cue := range c.Demuxer.pending {
+			c.pending = append(c.pending, c.newCueTiming(cue))
+		}
+		c.Demuxer.pending = nil
+	}
+}
+
+//ReadAll returns the timing of all cues until the end of the stream
+func (c *Correlator) ReadAll() (timings []*CueTiming, err error) {
+	for {
+		timing, err := c.Next()
+		if err == io.EOF {
+			return timings, nil
+		}
+		if err != nil {
+			return timings, err
+		}
+		timings = append(timings, timing)
+	}
+}
+
+func (c *Correlator) newCueTiming(cue *Cue) *CueTiming {
+	timing := &CueTiming{Cue: cue}
+	if cue.PCR != nil {
+		arrivalPTS := (*cue.PCR / 300) % common.PTSModulus
+		timing.ArrivalPTS = &arrivalPTS
+	}
+
+	if program, ok := c.programs[cue.ProgramNumber]; ok {
+		if videoPID, ok := videoPIDOf(program); ok {
+			timing.VideoPID = &videoPID
+		}
+	}
+
+	splicer, ok := cue.Parser.(interface {
+		SplicePTS() (uint64, bool)
+	})
+	if cue.Parser == nil || !ok {
+		return timing
+	}
+	if splicePTS, ok := splicer.SplicePTS(); ok {
+		timing.SplicePTS = &splicePTS
+		if timing.ArrivalPTS != nil {
+			preroll := common.PTSDiffDuration(splicePTS, *timing.ArrivalPTS)
+			timing.Preroll = &preroll
+			timing.InsufficientPreroll = preroll < c.MinPreroll
+		}
+	}
+	return timing
+}
+
+//settled reports whether the timing will not be changed by the coming packets
+//It is also settled once the PCR of the program passes the splice point by nearestVideoPTSWindow, since frames arrive before their PTS,
+//so that a splice point which no video frame reaches(e.g. the video stops) does not wait until the end of the stream
+func (c *Correlator) settled(timing *CueTiming) bool {
+	if timing.SplicePTS == nil || timing.VideoPID == nil {
+		return true
+	}
+	if timing.NearestVideoPTS != nil && common.PTSDiff(*timing.NearestVideoPTS, *timing.SplicePTS) > nearestVideoPTSWindow {
+		return true
+	}
+	program, ok := c.programs[timing.ProgramNumber]
+	if !ok {
+		return false
+	}
+	pcr, ok := c.pcrs[program.PCRPID]
+	return ok && common.PTSDiff((pcr/300)%common.PTSModulus, *timing.SplicePTS) > nearestVideoPTSWindow
+}
+
+func (c *Correlator) handlePacket(pkt *Packet, program *Program) {
+	if program == nil {
+		return
+	}
+
+	videoPID, ok := videoPIDOf(program)
+	if !ok || pkt.PID != videoPID || !pkt.PayloadUnitStartIndicator || pkt.TransportErrorIndicator {
+		return
+	}
+
+	pts, ok := decodePESPTS(pkt.Payload)
+	if !ok {
+		return
+	}
+	for _, timing := range c.pending {
+		if timing.SplicePTS == nil || timing.ProgramNumber != program.ProgramNumber {
+			continue
+		}
+		if timing.NearestVideoPTS == nil || abs(common.PTSDiff(pts, *timing.SplicePTS)) < abs(common.PTSDiff(*timing.NearestVideoPTS, *timing.SplicePTS)) {
+			nearest := pts
+			timing.NearestVideoPTS = &nearest
+		}
+	}
+}
+
+//videoPIDOf returns the PID of the first video stream of program
+func videoPIDOf(program *Program) (pid uint16, ok bool) {
+	for _, stream := range program.Streams {
+		if videoStreamTypes[stream.StreamType] {
+			return stream.PID, true
+		}
+	}
+	return 0, false
+}
+
+//decodePESPTS returns the PTS of the PES packet starting in payload, see ISO/IEC 13818-1 2.4.3.6
+func decodePESPTS(payload []byte) (pts uint64, ok bool) {
+	r := common.NewBitReader(payload)
+	if r.Bits("packet_start_code_prefix", 24) != 0x000001 {
+		return 0, false
+	}
+	r.Uint8("stream_id")
+	r.Uint16("PES_packet_length")
+	if r.Bits("marker", 2) != 0x02 {
+		return 0, false //no optional PES header, e.g. padding stream
+	}
+	r.Bits("flags", 6)
+	ptsDTSFlags := r.Bits("PTS_DTS_flags", 2)
+	r.Bits("flags", 6)
+	r.Uint8("PES_header_data_length")
+	if ptsDTSFlags&0x02 == 0 {
+		return 0, false
+	}
+
+	r.Bits("prefix", 4)
+	pts = r.Bits("PTS[32..30]", 3) << 30
+	r.Bool("marker_bit")
+	pts |= r.Bits("PTS[29..15]", 15) << 15
+	r.Bool("marker_bit")
+	pts |= r.Bits("PTS[14..0]", 15)
+	r.Bool("marker_bit")
+
+	return pts, r.Err() == nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
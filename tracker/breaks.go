@@ -0,0 +1,201 @@
+package tracker
+
+import (
+	"strconv"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//BreakEventKind tells what happens to a break
+type BreakEventKind string
+
+//Kinds of break events
+const (
+	BreakStarted        BreakEventKind = "started"         //a splice_insert with out_of_network_indicator set
+	BreakReturned       BreakEventKind = "returned"        //a splice_insert with out_of_network_indicator unset ends the open break
+	BreakAutoReturned   BreakEventKind = "auto_returned"   //the break ends by auto_return at the end of break_duration
+	BreakEarlyReturn    BreakEventKind = "early_return"    //the break is ended before the end of break_duration
+	BreakCanceled       BreakEventKind = "canceled"        //the open break is canceled by splice_event_cancel_indicator
+	BreakOverdue        BreakEventKind = "overdue"         //break_duration without auto_return passes before the return, the break stays open
+	BreakNeverReturned  BreakEventKind = "never_returned"  //the break is still open when the tracker is flushed
+	BreakOrphanedReturn BreakEventKind = "orphaned_return" //a return without an open break of the same splice_event_id
+	BreakDuplicateOut   BreakEventKind = "duplicate_out"   //an out of the open break, e.g. a repeated cue
+)
+
+//Break is a break of the network started by splice_insert with out_of_network_indicator set
+type Break struct {
+	EventID         uint32  `json:"splice_event_id"`
+	UniqueProgramID *uint16 `json:"unique_program_id,omitempty"`
+
+	OutPTS     uint64  `json:"out_pts"`
+	Duration   *uint64 `json:"break_duration,omitempty"` //90 kHz ticks
+	AutoReturn bool    `json:"auto_return"`
+	ReturnPTS  *uint64 `json:"return_pts,omitempty"` //set once the break is returned or canceled
+	Overdue    bool    `json:"overdue"`              //break_duration has passed without the return
+}
+
+//ExpectedReturnPTS returns OutPTS plus break_duration, where the break returns by itself if AutoReturn is set
+//ok is false if there is no break_duration
+func (brk *Break) ExpectedReturnPTS() (pts uint64, ok bool) {
+	if brk.Duration == nil {
+		return 0, false
+	}
+	return common.AddPTS(brk.OutPTS, *brk.Duration), true
+}
+
+//BreakEvent is reported by BreakTracker for every change of the open breaks and every unexpected splice_insert
+type BreakEvent struct {
+	Kind BreakEventKind `json:"kind"`
+	PTS  uint64         `json:"pts"` //when the event happens, the expected return for BreakAutoReturned and BreakOverdue
+
+	Break *Break `json:"break,omitempty"` //nil for BreakOrphanedReturn
+	//SpliceInsert is the splice_insert causing the event, nil for the events caused by the time, e.g. BreakAutoReturned
+	SpliceInsert *common.SpliceInsert `json:"splice_insert,omitempty"`
+	Reason       string               `json:"reason,omitempty"` //why the event happens if it is not told by SpliceInsert
+}
+
+//BreakTracker follows the out_of_network_indicator transitions of the splice_insert commands fed in stream order, per splice_event_id
+type BreakTracker struct {
+	//ReturnTolerance is how far a return may be off the end of break_duration before it is early or overdue, 0 by default
+	ReturnTolerance time.Duration
+
+	open         []*Break          //in the order of the outs
+	autoReturned map[uint32]*Break //splice_event_id -> the break returned by auto_return, whose return is not an orphan
+	pts          uint64            //the most recent PTS
+}
+
+//NewBreakTracker returns a BreakTracker without open breaks
+func NewBreakTracker() *BreakTracker {
+	return &BreakTracker{}
+}
+
+//Open returns the breaks which are open, in the order of their outs
+func (t *BreakTracker) Open() []*Break {
+	return append([]*Break{}, t.open...)
+}
+
+//FeedSection feeds the splice_insert of a section decoded by any schema, at the splice time of the section
+//The splice time of splice_immediate_flag or component splice mode is the most recent PTS fed, other commands only advance the time
+func (t *BreakTracker) FeedSection(section common.Parser) []BreakEvent {
+	pts, ok := splicePTSOf(section)
+	if !ok {
+		pts = t.pts
+	}
+	spliceInsert := spliceInsertOf(section)
+	if spliceInsert == nil {
+		return t.Advance(pts)
+	}
+	return t.Feed(spliceInsert, pts)
+}
+
+//Feed applies spliceInsert taking effect at pts, the breaks reaching the end of break_duration before pts are reported first
+//A return of a break which has already auto returned confirms the auto return and is not reported
+func (t *BreakTracker) Feed(spliceInsert *common.SpliceInsert, pts uint64) []BreakEvent {
+	events := t.Advance(pts)
+	if spliceInsert.SpliceEventCancelIndicator {
+		if brk := t.find(spliceInsert.SpliceEventID); brk != nil {
+			events = append(events, t.close(brk, BreakCanceled, pts, spliceInsert, ""))
+		}
+		return events
+	}
+	if spliceInsert.OutOfNetworkIndicator == nil {
+		return events
+	}
+
+	if *spliceInsert.OutOfNetworkIndicator {
+		return append(events, t.out(spliceInsert, pts))
+	}
+	return append(events, t.in(spliceInsert, pts)...)
+}
+
+//Advance moves the time to pts and reports the breaks reaching the end of break_duration(plus ReturnTolerance)
+//Breaks with auto_return are returned, the others are reported as overdue once
+func (t *BreakTracker) Advance(pts uint64) (events []BreakEvent) {
+	t.pts = pts
+	tolerance := int64(common.DurationToTicks(t.ReturnTolerance))
+	for _, brk := range t.Open() {
+		expected, ok := brk.ExpectedReturnPTS()
+		if !ok || brk.Overdue || common.PTSDiff(pts, expected) <= tolerance {
+			continue
+		}
+		if brk.AutoReturn {
+			if t.autoReturned == nil {
+				t.autoReturned = map[uint32]*Break{}
+			}
+			t.autoReturned[brk.EventID] = brk
+			events = append(events, t.close(brk, BreakAutoReturned, expected, nil, ""))
+			continue
+		}
+		brk.Overdue = true
+		reason := "not returned within break_duration of " + common.TicksToDuration(*brk.Duration).String() + " without auto_return"
+		events = append(events, BreakEvent{Kind: BreakOverdue, PTS: expected, Break: brk, Reason: reason})
+	}
+	return events
+}
+
+//Flush reports the breaks which are still open as never returned, e.g. at the end of the stream, and removes them
+func (t *BreakTracker) Flush() (events []BreakEvent) {
+	for _, brk := range t.open {
+		events = append(events, BreakEvent{Kind: BreakNeverReturned, PTS: t.pts, Break: brk, Reason: "no return until PTS " + strconv.FormatUint(t.pts, 10)})
+	}
+	t.open = nil
+	return events
+}
+
+func (t *BreakTracker) out(spliceInsert *common.SpliceInsert, pts uint64) BreakEvent {
+	if open := t.find(spliceInsert.SpliceEventID); open != nil {
+		reason := "already out since PTS " + strconv.FormatUint(open.OutPTS, 10)
+		return BreakEvent{Kind: BreakDuplicateOut, PTS: pts, Break: open, SpliceInsert: spliceInsert, Reason: reason}
+	}
+
+	delete(t.autoReturned, spliceInsert.SpliceEventID)
+	brk := &Break{EventID: spliceInsert.SpliceEventID, UniqueProgramID: spliceInsert.UniqueProgramID, OutPTS: pts}
+	if spliceInsert.BreakDuration != nil {
+		duration := spliceInsert.BreakDuration.Duration
+		brk.Duration = &duration
+		brk.AutoReturn = spliceInsert.BreakDuration.AutoReturn
+	}
+	t.open = append(t.open, brk)
+	return BreakEvent{Kind: BreakStarted, PTS: pts, Break: brk, SpliceInsert: spliceInsert}
+}
+
+func (t *BreakTracker) in(spliceInsert *common.SpliceInsert, pts uint64) []BreakEvent {
+	brk := t.find(spliceInsert.SpliceEventID)
+	if brk == nil {
+		if _, ok := t.autoReturned[spliceInsert.SpliceEventID]; ok {
+			delete(t.autoReturned, spliceInsert.SpliceEventID)
+			return nil
+		}
+		reason := "no open break of splice_event_id " + hexID(spliceInsert.SpliceEventID)
+		return []BreakEvent{{Kind: BreakOrphanedReturn, PTS: pts, SpliceInsert: spliceInsert, Reason: reason}}
+	}
+
+	tolerance := int64(common.DurationToTicks(t.ReturnTolerance))
+	if expected, ok := brk.ExpectedReturnPTS(); ok && common.PTSDiff(expected, pts) > tolerance {
+		reason := common.PTSDiffDuration(expected, pts).String() + " before the end of break_duration"
+		return []BreakEvent{t.close(brk, BreakEarlyReturn, pts, spliceInsert, reason)}
+	}
+	return []BreakEvent{t.close(brk, BreakReturned, pts, spliceInsert, "")}
+}
+
+//close removes brk from the open breaks
+func (t *BreakTracker) close(brk *Break, kind BreakEventKind, pts uint64, spliceInsert *common.SpliceInsert, reason string) BreakEvent {
+	for i, open := range t.open {
+		if open == brk {
+			t.open = append(t.open[:i], t.open[i+1:]...)
+			break
+		}
+	}
+	brk.ReturnPTS = &pts
+	return BreakEvent{Kind: kind, PTS: pts, Break: brk, SpliceInsert: spliceInsert, Reason: reason}
+}
+
+func (t *BreakTracker) find(eventID uint32) *Break {
+	for _, brk := range t.open {
+		if brk.EventID == eventID {
+			return brk
+		}
+	}
+	return nil
+}
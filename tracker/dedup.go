@@ -0,0 +1,118 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//DefaultRepeatWindow is the Window of NewDeduplicator, longer than the time encoders keep repeating a cue before its splice point
+const DefaultRepeatWindow = 5 * time.Minute
+
+//SeenCue is an event signaled by one or more repeated sections
+type SeenCue struct {
+	Section   common.Parser `json:"scte35"` //the first section signaling the event
+	FirstSeen time.Time     `json:"first_seen"`
+	LastSeen  time.Time     `json:"last_seen"`
+	Repeats   int           `json:"repeats"` //number of times seen after the first one
+
+	key string
+}
+
+//cueKey identifies the event of a section, CRC_32, pts_adjustment and the length fields are left out
+type cueKey struct {
+	SpliceCommandType          common.SpliceCommandType `json:"splice_command_type"`
+	SpliceEventID              *uint32                  `json:"splice_event_id,omitempty"`
+	SpliceEventCancelIndicator bool                     `json:"splice_event_cancel_indicator,omitempty"`
+	OutOfNetworkIndicator      *bool                    `json:"out_of_network_indicator,omitempty"`
+	SplicePTS                  *uint64                  `json:"splice_pts,omitempty"` //with pts_adjustment applied, nil for immediate splices
+	SpliceDescriptors          json.RawMessage          `json:"splice_descriptors"`   //including segmentation_event_id
+}
+
+//Deduplicator recognises the sections repeating the same event, so that each event is acted on once
+//Sections are repeats if they have the same splice_command_type, splice_event_id(and the role of splice_insert),
+//effective splice time and splice descriptors(including segmentation_event_id), whatever their CRC_32 and pts_adjustment
+type Deduplicator struct {
+	//Window is how long an event is remembered after it is last seen, 0 remembers events forever
+	Window time.Duration
+
+	cues  []*SeenCue //in the order of FirstSeen
+	index map[string]*SeenCue
+}
+
+//NewDeduplicator returns a Deduplicator with Window of DefaultRepeatWindow, the zero value remembers events forever
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{Window: DefaultRepeatWindow}
+}
+
+//Observe records section seen at seenAt, first is true if it is the first section of its event within Window
+//The returned SeenCue is updated by the repeats observed later
+func (d *Deduplicator) Observe(section common.Parser, seenAt time.Time) (cue *SeenCue, first bool, err error) {
+	key, err := keyOf(section)
+	if err != nil {
+		return nil, false, err
+	}
+	d.forget(seenAt)
+	if d.index == nil {
+		d.index = map[string]*SeenCue{}
+	}
+
+	if cue, ok := d.index[key]; ok {
+		cue.Repeats++
+		if seenAt.After(cue.LastSeen) {
+			cue.LastSeen = seenAt
+		}
+		return cue, false, nil
+	}
+	cue = &SeenCue{Section: section, FirstSeen: seenAt, LastSeen: seenAt, key: key}
+	d.cues = append(d.cues, cue)
+	d.index[key] = cue
+	return cue, true, nil
+}
+
+//Seen returns the events remembered, in the order they are first seen
+func (d *Deduplicator) Seen() []*SeenCue {
+	return append([]*SeenCue{}, d.cues...)
+}
+
+//forget drops the events last seen more than Window before now
+func (d *Deduplicator) forget(now time.Time) {
+	if d.Window <= 0 {
+		return
+	}
+	cues := d.cues[:0]
+	for _, cue := range d.cues {
+		if now.Sub(cue.LastSeen) > d.Window {
+			delete(d.index, cue.key)
+			continue
+		}
+		cues = append(cues, cue)
+	}
+	d.cues = cues
+}
+
+func keyOf(section common.Parser) (string, error) {
+	header := headerOf(section)
+	if header == nil {
+		return "", errors.New("Unsupported Section: the section is not decoded by any schema")
+	}
+	spliceDescriptors, err := spliceDescriptorsJSONOf(section)
+	if err != nil {
+		return "", err
+	}
+
+	key := cueKey{SpliceCommandType: header.SpliceCommandType, SpliceDescriptors: spliceDescriptors}
+	if spliceInsert := spliceInsertOf(section); spliceInsert != nil {
+		key.SpliceEventID = &spliceInsert.SpliceEventID
+		key.SpliceEventCancelIndicator = spliceInsert.SpliceEventCancelIndicator
+		key.OutOfNetworkIndicator = spliceInsert.OutOfNetworkIndicator
+	}
+	if pts, ok := splicePTSOf(section); ok {
+		key.SplicePTS = &pts
+	}
+
+	output, err := json.Marshal(key)
+	return string(output), err
+}
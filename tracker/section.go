@@ -0,0 +1,88 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+
+	schema_2013 "github.com/chanyk-joseph/scte35_decoder/2013"
+	schema_2017 "github.com/chanyk-joseph/scte35_decoder/2017"
+	schema_2022 "github.com/chanyk-joseph/scte35_decoder/2022"
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//segmentationDescriptorsOf returns the segmentation descriptors of a section decoded by any schema, in the order of the descriptor loop
+func segmentationDescriptorsOf(section common.Parser) (segDescs []*common.SegmentationDescriptor) {
+	switch scte35 := section.(type) {
+	case *schema_2013.SCTE35:
+		for _, spliceDesc := range scte35.SpliceDescriptors {
+			if spliceDesc.SegmentationDescriptor != nil {
+				segDescs = append(segDescs, spliceDesc.SegmentationDescriptor)
+			}
+		}
+	case *schema_2017.SCTE35:
+		for _, spliceDesc := range scte35.SpliceDescriptors {
+			if spliceDesc.SegmentationDescriptor != nil {
+				segDescs = append(segDescs, &spliceDesc.SegmentationDescriptor.SegmentationDescriptor)
+			}
+		}
+	case *schema_2022.SCTE35:
+		for _, spliceDesc := range scte35.SpliceDescriptors {
+			if spliceDesc.SegmentationDescriptor != nil {
+				segDescs = append(segDescs, &spliceDesc.SegmentationDescriptor.SegmentationDescriptor)
+			}
+		}
+	}
+	return segDescs
+}
+
+//splicePTSOf returns the splice time of section with pts_adjustment applied, see SplicePTS of the schemas
+func splicePTSOf(section common.Parser) (pts uint64, ok bool) {
+	splicer, ok := section.(interface {
+		SplicePTS() (uint64, bool)
+	})
+	if !ok {
+		return 0, false
+	}
+	return splicer.SplicePTS()
+}
+
+//spliceInsertOf returns splice_insert of a section decoded by any schema, nil for the other commands
+func spliceInsertOf(section common.Parser) *common.SpliceInsert {
+	switch scte35 := section.(type) {
+	case *schema_2013.SCTE35:
+		return scte35.SpliceInsert
+	case *schema_2017.SCTE35:
+		return scte35.SpliceInsert
+	case *schema_2022.SCTE35:
+		if scte35.SpliceInsert != nil {
+			return &scte35.SpliceInsert.SpliceInsert
+		}
+	}
+	return nil
+}
+
+//headerOf returns the fields shared by all schemas of a section, nil if section is not decoded by any schema
+func headerOf(section common.Parser) *common.SCTE35 {
+	switch scte35 := section.(type) {
+	case *schema_2013.SCTE35:
+		return &scte35.SCTE35
+	case *schema_2017.SCTE35:
+		return &scte35.SCTE35
+	case *schema_2022.SCTE35:
+		return &scte35.SCTE35
+	}
+	return nil
+}
+
+//spliceDescriptorsJSONOf returns the splice descriptors of a section decoded by any schema in JSON
+func spliceDescriptorsJSONOf(section common.Parser) (json.RawMessage, error) {
+	switch scte35 := section.(type) {
+	case *schema_2013.SCTE35:
+		return json.Marshal(scte35.SpliceDescriptors)
+	case *schema_2017.SCTE35:
+		return json.Marshal(scte35.SpliceDescriptors)
+	case *schema_2022.SCTE35:
+		return json.Marshal(scte35.SpliceDescriptors)
+	}
+	return nil, errors.New("Unsupported Section Of Schema " + section.SchemaVersion())
+}
@@ -0,0 +1,261 @@
+package tracker
+
+import (
+	"strconv"
+	"time"
+
+	common "github.com/chanyk-joseph/scte35_decoder/common"
+)
+
+//SegmentCategory is a kind of segments whose starts and ends are paired by SegmentTracker
+type SegmentCategory string
+
+//Categories of tracked segments
+const (
+	CategoryProgram              SegmentCategory = "program"
+	CategoryChapter              SegmentCategory = "chapter"
+	CategoryAdvertisement        SegmentCategory = "advertisement"         //Provider/Distributor Advertisement
+	CategoryPlacementOpportunity SegmentCategory = "placement_opportunity" //Provider/Distributor(Overlay) Placement Opportunity
+)
+
+//segmentStartTypes are the segmentation_type_id starting a tracked segment
+var segmentStartTypes = map[common.SegmentationTypeID]SegmentCategory{
+	0x10: CategoryProgram,              //Program Start
+	0x20: CategoryChapter,              //Chapter Start
+	0x30: CategoryAdvertisement,        //Provider Advertisement Start
+	0x32: CategoryAdvertisement,        //Distributor Advertisement Start
+	0x34: CategoryPlacementOpportunity, //Provider Placement Opportunity Start
+	0x36: CategoryPlacementOpportunity, //Distributor Placement Opportunity Start
+	0x38: CategoryPlacementOpportunity, //Provider Overlay Placement Opportunity Start
+	0x3A: CategoryPlacementOpportunity, //Distributor Overlay Placement Opportunity Start
+}
+
+//segmentEndTypes maps the segmentation_type_id ending a tracked segment to the one starting it
+var segmentEndTypes = map[common.SegmentationTypeID]common.SegmentationTypeID{
+	0x11: 0x10, //Program End
+	0x12: 0x10, //Program Early Termination
+	0x21: 0x20, //Chapter End
+	0x31: 0x30, //Provider Advertisement End
+	0x33: 0x32, //Distributor Advertisement End
+	0x35: 0x34, //Provider Placement Opportunity End
+	0x37: 0x36, //Distributor Placement Opportunity End
+	0x39: 0x38, //Provider Overlay Placement Opportunity End
+	0x3B: 0x3A, //Distributor Overlay Placement Opportunity End
+}
+
+//SegmentEventKind tells what happens to a segment
+type SegmentEventKind string
+
+//Kinds of segment events
+const (
+	SegmentOpened         SegmentEventKind = "opened"          //a segment is started
+	SegmentProgress       SegmentEventKind = "progress"        //an open segment is started again with another segment_num
+	SegmentClosed         SegmentEventKind = "closed"          //a segment is ended, chapters are also closed with their program
+	SegmentCanceled       SegmentEventKind = "canceled"        //an open segment is canceled by segmentation_event_cancel_indicator
+	SegmentOrphanedEnd    SegmentEventKind = "orphaned_end"    //an end without an open segment of the same segmentation_event_id and type
+	SegmentDuplicateStart SegmentEventKind = "duplicate_start" //a start of an open segment with the same segment_num, e.g. a repeated cue
+	SegmentExpired        SegmentEventKind = "expired"         //an open segment is not ended within its segmentation_duration
+)
+
+//Segment is a program, chapter, advertisement or placement opportunity started by a segmentation descriptor
+type Segment struct {
+	EventID   uint32                    `json:"segmentation_event_id"`
+	TypeID    common.SegmentationTypeID `json:"segmentation_type_id"` //segmentation_type_id of the start
+	Category  SegmentCategory           `json:"category"`
+	UpidInHex string                    `json:"segmentation_upid_in_hex,omitempty"`
+
+	SegmentNum       uint8 `json:"segment_num"`
+	SegmentsExpected uint8 `json:"segments_expected"`
+
+	StartPTS uint64  `json:"start_pts"`
+	Duration *uint64 `json:"segmentation_duration,omitempty"` //90 kHz ticks
+	EndPTS   *uint64 `json:"end_pts,omitempty"`               //set once the segment is closed, canceled or expired
+
+	//Parent is the program a chapter is nested in, nil for the others
+	Parent *Segment `json:"-"`
+}
+
+//PlannedEndPTS returns StartPTS plus segmentation_duration, ok is false if there is no segmentation_duration
+func (segment *Segment) PlannedEndPTS() (pts uint64, ok bool) {
+	if segment.Duration == nil {
+		return 0, false
+	}
+	return common.AddPTS(segment.StartPTS, *segment.Duration), true
+}
+
+//SegmentEvent is reported by SegmentTracker for every change of the open segments and every unexpected descriptor
+type SegmentEvent struct {
+	Kind SegmentEventKind `json:"kind"`
+	PTS  uint64           `json:"pts"` //when the event happens, the planned end of the segment for SegmentExpired
+
+	Segment *Segment `json:"segment,omitempty"` //nil for SegmentOrphanedEnd
+	//Descriptor is the segmentation descriptor causing the event, nil for the events caused by others, e.g. SegmentExpired
+	Descriptor *common.SegmentationDescriptor `json:"segmentation_descriptor,omitempty"`
+	Reason     string                         `json:"reason,omitempty"` //why the event happens if it is not told by Descriptor
+}
+
+//SegmentTracker pairs the starts and ends of the segmentation descriptors fed in stream order by segmentation_event_id and segmentation_type_id
+//Chapters started within an open program are nested in it and closed with it
+type SegmentTracker struct {
+	//ExpiryTolerance is how long a segment may stay open after its segmentation_duration before it expires, 0 by default
+	ExpiryTolerance time.Duration
+
+	open []*Segment //in the order of the starts
+	pts  uint64     //the most recent PTS
+}
+
+//NewSegmentTracker returns a SegmentTracker without open segments
+func NewSegmentTracker() *SegmentTracker {
+	return &SegmentTracker{}
+}
+
+//Open returns the segments which are open, in the order of their starts
+func (t *SegmentTracker) Open() []*Segment {
+	return append([]*Segment{}, t.open...)
+}
+
+//FeedSection feeds the segmentation descriptors of a section decoded by any schema, at the splice time of the section
+//The splice time of an immediate splice is the most recent PTS fed
+func (t *SegmentTracker) FeedSection(section common.Parser) (events []SegmentEvent) {
+	pts, ok := splicePTSOf(section)
+	if !ok {
+		pts = t.pts
+	}
+	for _, segDesc := range segmentationDescriptorsOf(section) {
+		events = append(events, t.Feed(segDesc, pts)...)
+	}
+	return events
+}
+
+//Feed applies segDesc taking effect at pts, the segments expired before pts are reported first
+//Descriptors of the segmentation_type_id which are not tracked(e.g. Content Identification) only advance the time
+func (t *SegmentTracker) Feed(segDesc *common.SegmentationDescriptor, pts uint64) []SegmentEvent {
+	events := t.Advance(pts)
+	if segDesc.SegmentationEventCancelIndicator {
+		return append(events, t.cancel(segDesc, pts)...)
+	}
+	if segDesc.SegmentationTypeID == nil {
+		return events
+	}
+
+	typeID := *segDesc.SegmentationTypeID
+	if category, ok := segmentStartTypes[typeID]; ok {
+		return append(events, t.start(segDesc, category, pts))
+	}
+	if startTypeID, ok := segmentEndTypes[typeID]; ok {
+		return append(events, t.end(segDesc, startTypeID, pts)...)
+	}
+	return events
+}
+
+//Advance moves the time to pts and reports the segments which are not ended within their segmentation_duration(plus ExpiryTolerance)
+func (t *SegmentTracker) Advance(pts uint64) (events []SegmentEvent) {
+	t.pts = pts
+	tolerance := int64(common.DurationToTicks(t.ExpiryTolerance))
+	for _, segment := range t.Open() {
+		plannedEnd, ok := segment.PlannedEndPTS()
+		if !ok || !t.isOpen(segment) || common.PTSDiff(pts, plannedEnd) <= tolerance {
+			continue
+		}
+		reason := "not ended within segmentation_duration of " + common.TicksToDuration(*segment.Duration).String()
+		events = append(events, t.close(segment, SegmentExpired, plannedEnd, nil, reason)...)
+	}
+	return events
+}
+
+func (t *SegmentTracker) start(segDesc *common.SegmentationDescriptor, category SegmentCategory, pts uint64) SegmentEvent {
+	segment := &Segment{
+		EventID:  segDesc.SegmentationEventID,
+		TypeID:   *segDesc.SegmentationTypeID,
+		Category: category,
+		StartPTS: pts,
+		Duration: segDesc.SegmentationDuration,
+	}
+	if segDesc.SegmentationUpidInHex != nil {
+		segment.UpidInHex = *segDesc.SegmentationUpidInHex
+	}
+	if segDesc.SegmentNum != nil && segDesc.SegmentsExpected != nil {
+		segment.SegmentNum = *segDesc.SegmentNum
+		segment.SegmentsExpected = *segDesc.SegmentsExpected
+	}
+
+	if open := t.find(segment.EventID, segment.TypeID); open != nil {
+		if open.SegmentNum == segment.SegmentNum {
+			reason := "already open since PTS " + strconv.FormatUint(open.StartPTS, 10)
+			return SegmentEvent{Kind: SegmentDuplicateStart, PTS: pts, Segment: open, Descriptor: segDesc, Reason: reason}
+		}
+		open.SegmentNum = segment.SegmentNum
+		open.SegmentsExpected = segment.SegmentsExpected
+		return SegmentEvent{Kind: SegmentProgress, PTS: pts, Segment: open, Descriptor: segDesc}
+	}
+
+	if category == CategoryChapter {
+		for i := len(t.open) - 1; i >= 0; i-- {
+			if t.open[i].Category == CategoryProgram {
+				segment.Parent = t.open[i]
+				break
+			}
+		}
+	}
+	t.open = append(t.open, segment)
+	return SegmentEvent{Kind: SegmentOpened, PTS: pts, Segment: segment, Descriptor: segDesc}
+}
+
+func (t *SegmentTracker) end(segDesc *common.SegmentationDescriptor, startTypeID common.SegmentationTypeID, pts uint64) []SegmentEvent {
+	segment := t.find(segDesc.SegmentationEventID, startTypeID)
+	if segment == nil {
+		reason := "no open segment of segmentation_event_id " + hexID(segDesc.SegmentationEventID) + " started by " + startTypeID.String()
+		return []SegmentEvent{{Kind: SegmentOrphanedEnd, PTS: pts, Descriptor: segDesc, Reason: reason}}
+	}
+	return t.close(segment, SegmentClosed, pts, segDesc, "")
+}
+
+//cancel cancels the open segments of segmentation_event_id of segDesc, whatever their type
+func (t *SegmentTracker) cancel(segDesc *common.SegmentationDescriptor, pts uint64) (events []SegmentEvent) {
+	for _, segment := range t.Open() {
+		if segment.EventID == segDesc.SegmentationEventID && t.isOpen(segment) {
+			events = append(events, t.close(segment, SegmentCanceled, pts, segDesc, "")...)
+		}
+	}
+	return events
+}
+
+//close removes segment from the open segments, the chapters nested in it are closed first
+func (t *SegmentTracker) close(segment *Segment, kind SegmentEventKind, pts uint64, segDesc *common.SegmentationDescriptor, reason string) (events []SegmentEvent) {
+	for _, child := range t.Open() {
+		if child.Parent == segment && t.isOpen(child) {
+			events = append(events, t.close(child, SegmentClosed, pts, nil, "program "+hexID(segment.EventID)+" is "+string(kind))...)
+		}
+	}
+
+	for i, open := range t.open {
+		if open == segment {
+			t.open = append(t.open[:i], t.open[i+1:]...)
+			break
+		}
+	}
+	segment.EndPTS = &pts
+	return append(events, SegmentEvent{Kind: kind, PTS: pts, Segment: segment, Descriptor: segDesc, Reason: reason})
+}
+
+func (t *SegmentTracker) find(eventID uint32, typeID common.SegmentationTypeID) *Segment {
+	for _, segment := range t.open {
+		if segment.EventID == eventID && segment.TypeID == typeID {
+			return segment
+		}
+	}
+	return nil
+}
+
+func (t *SegmentTracker) isOpen(segment *Segment) bool {
+	for _, open := range t.open {
+		if open == segment {
+			return true
+		}
+	}
+	return false
+}
+
+func hexID(id uint32) string {
+	return "0x" + strconv.FormatUint(uint64(id), 16)
+}